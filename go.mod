@@ -5,15 +5,16 @@ go 1.25.7
 require (
 	github.com/getsentry/sentry-go v0.35.2
 	github.com/gorilla/websocket v1.5.3
+	github.com/pelletier/go-toml/v2 v2.2.4
 	github.com/sethvargo/go-envconfig v1.1.0
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/sys v0.18.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/sys v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
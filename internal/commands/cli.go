@@ -0,0 +1,327 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"task-runner-launcher/internal/config"
+	"task-runner-launcher/internal/http"
+	"task-runner-launcher/internal/limits"
+	"task-runner-launcher/internal/logs"
+	"task-runner-launcher/internal/retry"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+type Command interface {
+	Execute() error
+}
+
+const usage = `Usage: %[1]s [command] [flags] [args]
+
+Commands:
+  launch <runner-name>...        Launch and manage the given runners (default)
+  validate <runner-name>...      Check the config file and environment for the given runners
+  print-config <runner-name>...  Print the effective config for the given runners
+  exec-once <runner-name>        Wait for a single task, run a runner for it, and exit
+  schema                         Print the JSON Schema of the config file
+  version                        Print the launcher version
+
+A runner's name is its ` + "`name`" + ` in the config file, defaulting to its ` + "`runner-type`" + `.
+Running the launcher without a command, e.g. ` + "`%[1]s javascript`" + `, is the same as ` + "`launch`" + `.
+Run ` + "`%[1]s <command> --help`" + ` to list the flags of a command. Flags must precede runner names.
+`
+
+const programName = "task-runner-launcher"
+
+// envFlags are the flags that override the equivalent env vars, accepted by all
+// commands that load the config.
+var envFlags = []struct {
+	name   string
+	envVar string
+	usage  string
+}{
+	{"log-level", "N8N_RUNNERS_LAUNCHER_LOG_LEVEL", "log level: debug, info, warn or error"},
+	{"config", "N8N_RUNNERS_CONFIG_PATH", "path to the config file"},
+	{"broker-uri", "N8N_RUNNERS_TASK_BROKER_URI", "URI of the task broker"},
+	{"runner-broker-uri", "N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI", "URI of the task broker for runners, if other than the broker URI"},
+	{"health-port", "N8N_RUNNERS_LAUNCHER_HEALTH_CHECK_PORT", "port for the launcher's health check server"},
+}
+
+// errHelp is returned when help was requested and printed.
+var errHelp = errors.New("help requested")
+
+// exitError is returned by a command to exit with a specific exit code.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
+// Run parses the command line args, excluding the program name, and executes
+// the selected command with the given env vars. Returns the exit code.
+func Run(args []string, environ []string, version string) int {
+	if len(args) > 0 && args[0] == limits.ExecCommand {
+		err := limits.Exec(args[1:]) // returns only on failure
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	cmd, err := parseCommand(args, environ, version, os.Stdout, os.Stderr)
+	if errors.Is(err, errHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	err = cmd.Execute()
+	var exitErr *exitError
+	switch {
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case err != nil:
+		logs.Error(err.Error())
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseCommand returns the command selected by the args. Help and usage errors
+// are printed to `stderr`, and command output goes to `stdout`.
+func parseCommand(args []string, environ []string, version string, stdout, stderr io.Writer) (Command, error) {
+	return parseCommandWithFlags(args, environ, nil, version, stdout, stderr)
+}
+
+// parseCommandWithFlags is `parseCommand` for args that may follow flags already
+// parsed, which overrode the env vars in `flagEnvVars`.
+func parseCommandWithFlags(args []string, environ []string, flagEnvVars []string, version string, stdout, stderr io.Writer) (Command, error) {
+	name, isDefault := "launch", true
+	if len(args) > 0 {
+		switch args[0] {
+		case "-h", "-help", "--help", "help":
+			fmt.Fprintf(stderr, usage, programName)
+			return nil, errHelp
+		default:
+			if isCommand(args[0]) {
+				name, isDefault, args = args[0], false, args[1:]
+			}
+		}
+	}
+
+	fs := flag.NewFlagSet(programName+" "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var overrides func() map[string]string
+	if name != "version" && name != "schema" {
+		overrides = registerEnvFlags(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+
+	if overrides != nil {
+		flagValues := overrides()
+		environ = withOverrides(environ, flagValues)
+		for key := range flagValues {
+			if !slices.Contains(flagEnvVars, key) {
+				flagEnvVars = append(flagEnvVars, key)
+			}
+		}
+		sort.Strings(flagEnvVars) // ensure consistent order
+	}
+
+	runnerNames := fs.Args()
+
+	// flags may also precede the command, e.g. `--config x.json validate javascript`
+	if isDefault && len(runnerNames) > 0 && isCommand(runnerNames[0]) {
+		return parseCommandWithFlags(runnerNames, environ, flagEnvVars, version, stdout, stderr)
+	}
+
+	switch name {
+	case "version":
+		return NewVersionCommand(version, stdout), nil
+	case "schema":
+		return NewSchemaCommand(stdout), nil
+	case "exec-once":
+		if len(runnerNames) != 1 {
+			fs.Usage()
+			return nil, errors.New("`exec-once` requires exactly one runner name")
+		}
+		return NewExecOnceCommand(runnerNames[0], environ, version), nil
+	}
+
+	if len(runnerNames) == 0 {
+		fmt.Fprintf(stderr, usage, programName)
+		return nil, errors.New("missing runner-name argument(s)")
+	}
+
+	switch name {
+	case "validate":
+		return NewValidateCommand(runnerNames, environ, stdout), nil
+	case "print-config":
+		return NewPrintConfigCommand(runnerNames, environ, flagEnvVars, stdout), nil
+	default:
+		return NewLaunchCommand(runnerNames, environ, version), nil
+	}
+}
+
+func isCommand(arg string) bool {
+	switch arg {
+	case "launch", "validate", "print-config", "exec-once", "schema", "version":
+		return true
+	default:
+		return false
+	}
+}
+
+// registerEnvFlags registers the env var flags on the flag set, and returns a
+// func returning the env vars to override after parsing, i.e. only those for
+// flags set on the command line.
+func registerEnvFlags(fs *flag.FlagSet) func() map[string]string {
+	values := make(map[string]*string, len(envFlags))
+	for _, f := range envFlags {
+		values[f.name] = fs.String(f.name, "", fmt.Sprintf("%s (overrides %s)", f.usage, f.envVar))
+	}
+
+	return func() map[string]string {
+		overrides := make(map[string]string)
+		fs.Visit(func(set *flag.Flag) {
+			for _, f := range envFlags {
+				if f.name == set.Name {
+					overrides[f.envVar] = *values[f.name]
+				}
+			}
+		})
+		return overrides
+	}
+}
+
+// withOverrides returns the env vars with the given overrides applied. An
+// overridden env var's `_FILE` variant is dropped, so that the override takes
+// precedence over it.
+func withOverrides(environ []string, overrides map[string]string) []string {
+	if len(overrides) == 0 {
+		return environ
+	}
+
+	result := make([]string, 0, len(environ)+len(overrides))
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		_, overridden := overrides[strings.TrimSuffix(key, "_FILE")]
+		if !overridden {
+			result = append(result, kv)
+		}
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // ensure consistent order
+
+	for _, key := range keys {
+		result = append(result, key+"="+overrides[key])
+	}
+
+	return result
+}
+
+// envMap converts env vars in the form of `os.Environ()` into a map.
+func envMap(environ []string) map[string]string {
+	envVars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			envVars[key] = value
+		}
+	}
+
+	return envVars
+}
+
+// recordingLookuper records the env vars found while loading the config.
+type recordingLookuper struct {
+	envconfig.Lookuper
+	found map[string]string
+}
+
+func (l *recordingLookuper) Lookup(key string) (string, bool) {
+	value, ok := l.Lookuper.Lookup(key)
+	if ok {
+		if l.found == nil {
+			l.found = make(map[string]string)
+		}
+		l.found[key] = value
+	}
+
+	return value, ok
+}
+
+// loadConfig loads the launcher config for the given runners from the env vars
+// found by the lookuper, and applies the launcher-wide settings from it.
+func loadConfig(runnerNames []string, lookuper envconfig.Lookuper) (*config.LauncherConfig, error) {
+	launcherConfig, err := config.LoadLauncherConfig(runnerNames, lookuper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logs.SetFormat(logs.ParseFormat(launcherConfig.BaseConfig.LogFormat))
+
+	configureBackoffs(launcherConfig.BaseConfig)
+
+	http.SetBrokerTLSConfig(launcherConfig.BrokerTLSConfig)
+
+	return launcherConfig, nil
+}
+
+// checkLimits returns an error if the resource limits of any of the runners
+// cannot be enforced, so that limits are never silently dropped.
+func checkLimits(launcherConfig *config.LauncherConfig, runnerNames []string) error {
+	var limitErrs []error
+	for _, runnerName := range runnerNames {
+		if err := limits.Enforceable(launcherConfig.RunnerConfig(runnerName).Limits); err != nil {
+			limitErrs = append(limitErrs, fmt.Errorf("runner %s: limits: %w", runnerName, err))
+		}
+	}
+
+	return errors.Join(limitErrs...)
+}
+
+// configureBackoffs sets the retry backoff for every broker-facing operation.
+func configureBackoffs(baseConfig *config.BaseConfig) {
+	backoffConfigs := map[string]*config.BackoffConfig{
+		http.OperationReadinessCheck:  baseConfig.ReadinessCheckBackoff,
+		http.OperationGrantTokenFetch: baseConfig.GrantTokenFetchBackoff,
+	}
+
+	for operationName, cfg := range backoffConfigs {
+		// already validated on config load
+		backoff, _ := retry.NewBackoff(cfg.Strategy, cfg.BaseDelay, cfg.MaxDelay)
+		retry.SetBackoff(operationName, backoff)
+		logs.Debugf("Using %s backoff for operation `%s`", cfg.Strategy, operationName)
+	}
+}
+
+// exitCode returns the conventional exit code for termination by a signal,
+// i.e. 128 + signal number, e.g. 143 for SIGTERM and 130 for SIGINT.
+func exitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+
+	return 1
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	environ := []string{"N8N_RUNNERS_CONFIG_PATH_FILE=/path/to/file", "N8N_RUNNERS_AUTH_TOKEN=token"}
+
+	tests := []struct {
+		name            string
+		args            []string
+		expected        Command
+		expectedError   string
+		expectedHelp    bool
+		expectedEnviron []string
+	}{
+		{
+			name:     "runner names without command launch runners",
+			args:     []string{"javascript", "python"},
+			expected: NewLaunchCommand([]string{"javascript", "python"}, environ, "1.2.3"),
+		},
+		{
+			name:     "launch command",
+			args:     []string{"launch", "javascript"},
+			expected: NewLaunchCommand([]string{"javascript"}, environ, "1.2.3"),
+		},
+		{
+			name:     "validate command",
+			args:     []string{"validate", "javascript"},
+			expected: &ValidateCommand{runnerNames: []string{"javascript"}, environ: environ},
+		},
+		{
+			name:     "print-config command",
+			args:     []string{"print-config", "javascript"},
+			expected: &PrintConfigCommand{runnerNames: []string{"javascript"}, environ: environ},
+		},
+		{
+			name:     "exec-once command",
+			args:     []string{"exec-once", "javascript"},
+			expected: NewExecOnceCommand("javascript", environ, "1.2.3"),
+		},
+		{
+			name:     "schema command",
+			args:     []string{"schema"},
+			expected: &SchemaCommand{},
+		},
+		{
+			name:     "version command",
+			args:     []string{"version"},
+			expected: &VersionCommand{version: "1.2.3"},
+		},
+		{
+			name: "flags override env vars",
+			args: []string{"launch", "--log-level", "debug", "--config", "/etc/runners.json", "--broker-uri", "http://broker:5679", "--health-port", "5690", "javascript"},
+			expected: NewLaunchCommand([]string{"javascript"}, []string{
+				"N8N_RUNNERS_AUTH_TOKEN=token",
+				"N8N_RUNNERS_CONFIG_PATH=/etc/runners.json",
+				"N8N_RUNNERS_LAUNCHER_HEALTH_CHECK_PORT=5690",
+				"N8N_RUNNERS_LAUNCHER_LOG_LEVEL=debug",
+				"N8N_RUNNERS_TASK_BROKER_URI=http://broker:5679",
+			}, "1.2.3"),
+		},
+		{
+			name: "print-config command with flags",
+			args: []string{"--log-level", "debug", "print-config", "--config", "/etc/runners.json", "javascript"},
+			expected: &PrintConfigCommand{
+				runnerNames: []string{"javascript"},
+				environ: []string{
+					"N8N_RUNNERS_AUTH_TOKEN=token",
+					"N8N_RUNNERS_LAUNCHER_LOG_LEVEL=debug",
+					"N8N_RUNNERS_CONFIG_PATH=/etc/runners.json",
+				},
+				flagEnvVars: []string{"N8N_RUNNERS_CONFIG_PATH", "N8N_RUNNERS_LAUNCHER_LOG_LEVEL"},
+			},
+		},
+		{
+			name: "flags before command",
+			args: []string{"--config", "/etc/runners.json", "validate", "javascript"},
+			expected: &ValidateCommand{runnerNames: []string{"javascript"}, environ: []string{
+				"N8N_RUNNERS_AUTH_TOKEN=token",
+				"N8N_RUNNERS_CONFIG_PATH=/etc/runners.json",
+			}},
+		},
+		{
+			name:         "help",
+			args:         []string{"--help"},
+			expectedHelp: true,
+		},
+		{
+			name:         "command help",
+			args:         []string{"validate", "-h"},
+			expectedHelp: true,
+		},
+		{
+			name:          "missing runner names",
+			args:          []string{},
+			expectedError: "missing runner-name argument(s)",
+		},
+		{
+			name:          "exec-once with multiple runners",
+			args:          []string{"exec-once", "javascript", "python"},
+			expectedError: "requires exactly one runner name",
+		},
+		{
+			name:          "unknown flag",
+			args:          []string{"--unknown", "javascript"},
+			expectedError: "flag provided but not defined: -unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd, err := parseCommand(tt.args, environ, "1.2.3", &stdout, &stderr)
+
+			switch {
+			case tt.expectedHelp:
+				assert.ErrorIs(t, err, errHelp)
+				assert.Contains(t, stderr.String(), "Usage")
+			case tt.expectedError != "":
+				assert.ErrorContains(t, err, tt.expectedError)
+			default:
+				require.NoError(t, err)
+				switch expected := tt.expected.(type) {
+				case *ValidateCommand:
+					expected.out = &stdout
+				case *PrintConfigCommand:
+					expected.out = &stdout
+				case *SchemaCommand:
+					expected.out = &stdout
+				case *VersionCommand:
+					expected.out = &stdout
+				}
+				assert.Equal(t, tt.expected, cmd)
+			}
+		})
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, NewVersionCommand("1.2.3", &out).Execute())
+	assert.Equal(t, "task-runner-launcher 1.2.3\n", out.String())
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"task-runner-launcher/internal/logs"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// ExecOnceCommand waits for a single task for the given runner, launches a
+// runner for it, and returns once the runner exits. Useful for debugging a
+// runner config end to end.
+type ExecOnceCommand struct {
+	runnerName string
+	environ    []string // in the form of `os.Environ()`
+	version    string   // launcher version
+}
+
+func NewExecOnceCommand(runnerName string, environ []string, version string) *ExecOnceCommand {
+	return &ExecOnceCommand{runnerName: runnerName, environ: environ, version: version}
+}
+
+func (c *ExecOnceCommand) Execute() error {
+	launcherConfig, err := loadConfig([]string{c.runnerName}, envconfig.MapLookuper(envMap(c.environ)))
+	if err != nil {
+		return err
+	}
+
+	if err := checkLimits(launcherConfig, []string{c.runnerName}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	received := make(chan os.Signal, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case sig := <-signals:
+			logs.Infof("Received %s, shutting down...", sig)
+			received <- sig
+			cancel()
+		}
+	}()
+
+	logger := newRunnerLogger(launcherConfig, c.runnerName)
+	if err := newRunnerLauncher(logger, c.version).runOnce(ctx, launcherConfig, c.runnerName); err != nil {
+		return err
+	}
+
+	select {
+	case sig := <-received:
+		return &exitError{code: exitCode(sig)}
+	default:
+		return nil
+	}
+}
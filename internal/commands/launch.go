@@ -2,131 +2,113 @@ package commands
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"os"
-	"os/exec"
+	"os/signal"
 	"sync"
+	"syscall"
 	"task-runner-launcher/internal/config"
-	"task-runner-launcher/internal/env"
-	"task-runner-launcher/internal/errs"
+	"task-runner-launcher/internal/errorreporting"
 	"task-runner-launcher/internal/http"
 	"task-runner-launcher/internal/logs"
-	"task-runner-launcher/internal/ws"
 	"time"
+
+	"github.com/sethvargo/go-envconfig"
 )
 
-type Command interface {
-	Execute() error
-}
+// configWatchInterval is how often the launcher checks the config file for changes.
+const configWatchInterval = 10 * time.Second
 
+// shutdownMargin is the extra time, on top of the grace period, that the
+// launcher waits for its goroutines to return after a shutdown signal.
+const shutdownMargin = 5 * time.Second
+
+// LaunchCommand launches and manages the given runners until the launcher
+// receives SIGTERM or SIGINT.
 type LaunchCommand struct {
-	logger *logs.Logger
+	runnerNames []string
+	environ     []string // in the form of `os.Environ()`
+	version     string   // launcher version
 }
 
-func NewLaunchCommand(logger *logs.Logger) *LaunchCommand {
-	return &LaunchCommand{logger: logger}
+func NewLaunchCommand(runnerNames []string, environ []string, version string) *LaunchCommand {
+	return &LaunchCommand{runnerNames: runnerNames, environ: environ, version: version}
 }
 
-func (c *LaunchCommand) Execute(launcherConfig *config.LauncherConfig, runnerType string) error {
-	c.logger.Info("Starting launcher goroutine...")
-
-	baseConfig := launcherConfig.BaseConfig
-	runnerConfig := launcherConfig.RunnerConfigs[runnerType]
-
-	// 1. change into working directory
-
-	if err := os.Chdir(runnerConfig.WorkDir); err != nil {
-		return fmt.Errorf("failed to chdir into configured dir (%s): %w", runnerConfig.WorkDir, err)
+func (c *LaunchCommand) Execute() error {
+	launcherConfig, err := loadConfig(c.runnerNames, envconfig.MapLookuper(envMap(c.environ)))
+	if err != nil {
+		return err
 	}
 
-	c.logger.Debugf("Changed into working directory: %s", runnerConfig.WorkDir)
-
-	// 2. prepare env vars to pass to runner
-
-	runnerEnv := env.PrepareRunnerEnv(baseConfig, runnerConfig, c.logger)
-	runnerServerURI := fmt.Sprintf("http://%s:%s", baseConfig.RunnerHealthCheckServerHost, runnerConfig.HealthCheckServerPort)
-
-	for {
-		// 3. check until task broker is ready
-
-		if err := http.CheckUntilBrokerReady(baseConfig.TaskBrokerURI, c.logger); err != nil {
-			return fmt.Errorf("encountered error while waiting for broker to be ready: %w", err)
-		}
-
-		// 4. fetch grant token for launcher
-
-		launcherGrantToken, err := http.FetchGrantToken(baseConfig.TaskBrokerURI, baseConfig.AuthToken)
-		if err != nil {
-			return fmt.Errorf("failed to fetch grant token for launcher: %w", err)
-		}
-
-		c.logger.Debug("Fetched grant token for launcher")
+	if err := checkLimits(launcherConfig, c.runnerNames); err != nil {
+		return err
+	}
 
-		// 5. connect to main and wait for task offer to be accepted
+	errorreporting.Init(launcherConfig.BaseConfig.Sentry)
+	defer errorreporting.Close()
 
-		handshakeCfg := ws.HandshakeConfig{
-			TaskType:            runnerConfig.RunnerType,
-			TaskBrokerServerURI: launcherConfig.BaseConfig.TaskBrokerURI,
-			GrantToken:          launcherGrantToken,
-		}
+	http.InitHealthCheckServer(launcherConfig.BaseConfig.HealthCheckServerPort)
 
-		err = ws.Handshake(handshakeCfg, c.logger)
-		switch {
-		case errors.Is(err, errs.ErrServerDown):
-			c.logger.Warn("Task broker is down, launcher will try to reconnect...")
-			time.Sleep(time.Second * 5)
-			continue // back to checking until broker ready
-		case err != nil:
-			return fmt.Errorf("handshake failed: %w", err)
-		}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		// 6. fetch grant token for runner
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
 
-		runnerGrantToken, err := http.FetchGrantToken(baseConfig.TaskBrokerURI, baseConfig.AuthToken)
-		if err != nil {
-			return fmt.Errorf("failed to fetch grant token for runner: %w", err)
-		}
-
-		c.logger.Debug("Fetched grant token for runner")
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
 
-		runnerEnv = append(runnerEnv, fmt.Sprintf("N8N_RUNNERS_GRANT_TOKEN=%s", runnerGrantToken))
+	go launcherConfig.WatchRunnerConfigs(ctx, c.runnerNames, configWatchInterval, reloadSignals)
 
-		// 8. launch runner
+	var wg sync.WaitGroup
 
-		c.logger.Debug("Task ready for pickup, launching runner...")
-		c.logger.Debugf("Command: %s", runnerConfig.Command)
-		c.logger.Debugf("Args: %v", runnerConfig.Args)
+	for _, runnerName := range c.runnerNames {
+		http.SetRunnerState(runnerName, http.StateWaitingForBroker)
 
-		ctx, cancelHealthMonitor := context.WithCancel(context.Background())
-		var wg sync.WaitGroup
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
 
-		cmd := exec.CommandContext(ctx, runnerConfig.Command, runnerConfig.Args...)
-		cmd.Env = runnerEnv
-		runnerPrefix := logs.GetRunnerPrefix(runnerType)
-		logLevel := logs.ParseLevel(launcherConfig.BaseConfig.LogLevel)
-		cmd.Stdout, cmd.Stderr = logs.GetRunnerWriters(logLevel, runnerPrefix)
+			logger := newRunnerLogger(launcherConfig, name)
+			if err := newRunnerLauncher(logger, c.version).run(ctx, launcherConfig, name); err != nil {
+				logger.Errorf("Failed to execute `launch` command: %v", err)
+				http.SetRunnerState(name, http.StateFailed)
+			}
+		}(runnerName)
+	}
 
-		if err := cmd.Start(); err != nil {
-			cancelHealthMonitor()
-			return fmt.Errorf("failed to start runner process: %w", err)
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
+
+	select {
+	case <-allDone:
+		return nil
+	case sig := <-signals:
+		logs.Infof("Received %s, shutting down...", sig)
+		cancel()
+
+		gracePeriod := time.Duration(launcherConfig.BaseConfig.ShutdownGracePeriod) * time.Second
+		select {
+		case <-allDone:
+			logs.Info("Shut down launcher")
+		case <-time.After(gracePeriod + shutdownMargin):
+			logs.Warn("Timed out waiting for launcher goroutines to stop, exiting anyway")
 		}
 
-		go http.ManageRunnerHealth(ctx, cmd, runnerServerURI, &wg, c.logger)
-
-		err = cmd.Wait()
-		if err != nil && err.Error() == "signal: killed" {
-			c.logger.Warn("Unresponsive runner process was terminated")
-		} else if err != nil {
-			c.logger.Errorf("Runner process exited with error: %v", err)
-		} else {
-			c.logger.Info("Runner process exited on idle timeout")
-		}
-		cancelHealthMonitor()
+		return &exitError{code: exitCode(sig)}
+	}
+}
 
-		wg.Wait()
+// newRunnerLogger creates the logger for the launcher goroutine managing the
+// runner with the given name.
+func newRunnerLogger(launcherConfig *config.LauncherConfig, runnerName string) *logs.Logger {
+	logLevel := logs.ParseLevel(launcherConfig.BaseConfig.LogLevel)
+	runnerType := launcherConfig.RunnerConfig(runnerName).RunnerType
 
-		// next runner will need to fetch a new grant token
-		runnerEnv = env.Clear(runnerEnv, env.EnvVarGrantToken)
-	}
+	return logs.NewLauncherLogger(logLevel, runnerType, runnerName)
 }
@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"task-runner-launcher/internal/config"
+	"task-runner-launcher/internal/env"
+	"text/tabwriter"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// redacted replaces secret values in printed config.
+const redacted = "<redacted>"
+
+// Sources of printed config values.
+const (
+	sourceDefault    = "default"
+	sourceEnv        = "env"
+	sourceFlag       = "flag"
+	sourceConfigFile = "config file"
+	sourceLauncher   = "launcher"
+
+	sourceEnvFromFiles = sourceConfigFile + " (env-from-files)"
+)
+
+// secretWords are the words that mark the value of an env var ending in one of
+// them, delimited by `_`, as secret.
+var secretWords = []string{"TOKEN", "SECRET", "PASSWORD", "PASS", "KEY", "DSN", "CREDENTIALS"}
+
+// launcherEnvVars are the runner env vars that the launcher sets itself, and
+// whether `env-overrides` may override them.
+var launcherEnvVars = map[string]bool{
+	env.EnvVarTaskBrokerURI:            false,
+	env.EnvVarHealthCheckServerEnabled: false,
+	env.EnvVarHealthCheckServerPort:    false,
+	env.EnvVarGrantToken:               false,
+	env.EnvVarAutoShutdownTimeout:      true,
+	env.EnvVarTaskTimeout:              true,
+}
+
+// PrintConfigCommand prints the effective config for the given runners, i.e.
+// after applying defaults, env vars, flags and the config file, followed by the
+// env that every runner is launched with. Every value is annotated with its
+// source, and secrets are redacted.
+type PrintConfigCommand struct {
+	runnerNames []string
+	environ     []string // in the form of `os.Environ()`
+	flagEnvVars []string // env vars overridden by flags
+	out         io.Writer
+}
+
+func NewPrintConfigCommand(runnerNames []string, environ []string, flagEnvVars []string, out io.Writer) *PrintConfigCommand {
+	return &PrintConfigCommand{runnerNames: runnerNames, environ: environ, flagEnvVars: flagEnvVars, out: out}
+}
+
+func (c *PrintConfigCommand) Execute() error {
+	lookuper := &recordingLookuper{Lookuper: envconfig.MapLookuper(envMap(c.environ))}
+	launcherConfig, err := loadConfig(c.runnerNames, lookuper)
+	if err != nil {
+		return err
+	}
+
+	// prepare runner envs upfront so that any warnings are logged before the output
+	runnerEnvs := make(map[string][]string, len(c.runnerNames))
+	for _, runnerName := range c.runnerNames {
+		runnerConfig := launcherConfig.RunnerConfig(runnerName)
+		logger := newRunnerLogger(launcherConfig, runnerName)
+		runnerEnv, err := env.AddEnvFromFiles(env.PrepareRunnerEnv(launcherConfig.BaseConfig, runnerConfig, logger), runnerConfig, logger)
+		if err != nil {
+			return err
+		}
+		runnerEnvs[runnerName] = launchEnv(runnerEnv, string(runnerConfig.HealthCheckServerPort), redacted, false)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "# launcher")
+	walkEnvFields(reflect.ValueOf(launcherConfig.BaseConfig).Elem(), "", func(key string, value reflect.Value) {
+		printValue(w, key, redactIfSecret(key, fmt.Sprint(value.Interface())), c.baseSource(key, lookuper.found))
+	})
+
+	for _, runnerName := range c.runnerNames {
+		runnerConfig := launcherConfig.RunnerConfig(runnerName)
+
+		fmt.Fprintf(w, "\n# runner %s\n", runnerName)
+		if err := printRunnerConfig(w, runnerConfig); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "\n# runner %s: env\n", runnerName)
+		for _, kv := range runnerEnvs[runnerName] {
+			key, value, _ := strings.Cut(kv, "=")
+			source := runnerEnvSource(key, runnerConfig)
+			if source == sourceEnvFromFiles {
+				value = redacted // file contents are secret, whatever the name
+			}
+			printValue(w, key, redactIfSecret(key, value), source)
+		}
+	}
+
+	return w.Flush()
+}
+
+// baseSource returns the source of the value of the base config env var.
+func (c *PrintConfigCommand) baseSource(key string, found map[string]string) string {
+	if slices.Contains(c.flagEnvVars, key) {
+		for _, f := range envFlags {
+			if f.envVar == key {
+				return fmt.Sprintf("%s (--%s)", sourceFlag, f.name)
+			}
+		}
+	}
+
+	if _, ok := found[key+"_FILE"]; ok {
+		return fmt.Sprintf("%s (%s_FILE)", sourceEnv, key)
+	}
+
+	if _, ok := found[key]; ok {
+		return sourceEnv
+	}
+
+	return sourceDefault
+}
+
+// printRunnerConfig prints every field of the runner config by its name in the
+// config file.
+func printRunnerConfig(w io.Writer, runnerConfig *config.RunnerConfig) error {
+	v := reflect.ValueOf(runnerConfig).Elem()
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			continue
+		}
+
+		value := v.Field(i)
+
+		source := sourceConfigFile
+		if value.IsZero() || runnerConfig.IsDefaulted(name) {
+			source = sourceDefault
+		}
+
+		if overrides, ok := value.Interface().(map[string]string); ok && overrides != nil {
+			redactedOverrides := make(map[string]string, len(overrides))
+			for key, value := range overrides {
+				redactedOverrides[key] = redactIfSecret(key, value)
+			}
+			value = reflect.ValueOf(redactedOverrides)
+		}
+
+		formatted, err := formatValue(value)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", name, err)
+		}
+
+		printValue(w, name, formatted, source)
+	}
+
+	return nil
+}
+
+// runnerEnvSource returns the source of the runner env var.
+func runnerEnvSource(key string, runnerConfig *config.RunnerConfig) string {
+	overridable, isLauncherEnvVar := launcherEnvVars[key]
+	_, overridden := runnerConfig.EnvOverrides[key]
+	_, fromFile := runnerConfig.EnvFromFiles[key]
+
+	switch {
+	case overridden && (!isLauncherEnvVar || overridable):
+		return sourceConfigFile + " (env-overrides)"
+	case fromFile && (!isLauncherEnvVar || overridable):
+		return sourceEnvFromFiles
+	case isLauncherEnvVar:
+		return sourceLauncher
+	default:
+		return sourceEnv + " (allowed-env)"
+	}
+}
+
+// walkEnvFields calls `fn` for every field of the struct that is read from an
+// env var, following nested structs and their `prefix` option.
+func walkEnvFields(v reflect.Value, prefix string, fn func(key string, value reflect.Value)) {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("env"), ",")
+		name = strings.TrimSpace(name)
+
+		if name != "" {
+			fn(prefix+name, value)
+			continue
+		}
+
+		if value.Kind() != reflect.Struct {
+			continue // not read from env
+		}
+
+		nestedPrefix := prefix
+		for _, opt := range strings.Split(opts, ",") {
+			if p, ok := strings.CutPrefix(strings.TrimSpace(opt), "prefix="); ok {
+				nestedPrefix += p
+			}
+		}
+		walkEnvFields(value, nestedPrefix, fn)
+	}
+}
+
+func formatValue(value reflect.Value) (string, error) {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if value.IsNil() {
+			return "", nil
+		}
+	}
+
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func printValue(w io.Writer, key, value, source string) {
+	fmt.Fprintf(w, "%s\t%s=%s\n", source, key, value)
+}
+
+// redactIfSecret redacts the value if the env var name marks it as secret, e.g.
+// `N8N_RUNNERS_AUTH_TOKEN` or `SENTRY_DSN`.
+func redactIfSecret(key, value string) string {
+	if value == "" {
+		return ""
+	}
+
+	words := strings.Split(strings.ToUpper(key), "_")
+	if slices.Contains(secretWords, words[len(words)-1]) {
+		return redacted
+	}
+
+	return value
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintConfigCommand(t *testing.T) {
+	workDir := t.TempDir()
+	dbHostPath := filepath.Join(t.TempDir(), "db-host")
+	require.NoError(t, os.WriteFile(dbHostPath, []byte("db.internal\n"), 0o600))
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"task-runners": [{
+			"runner-type": "javascript",
+			"workdir": "` + workDir + `",
+			"command": "node",
+			"args": ["start.js"],
+			"allowed-env": ["CUSTOM_VAR", "CUSTOM_API_KEY"],
+			"env-overrides": {"NODE_OPTIONS": "--max-old-space-size=1024", "DB_PASSWORD": "hunter2", "N8N_RUNNERS_GRANT_TOKEN": "ignored"},
+			"env-from-files": {"DB_HOST": "` + dbHostPath + `"}
+		}]
+	}`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("auth-token-from-file\n"), 0o600))
+
+	t.Setenv("CUSTOM_VAR", "custom-value")
+	t.Setenv("CUSTOM_API_KEY", "api-key-value")
+
+	environ := []string{
+		"N8N_RUNNERS_AUTH_TOKEN_FILE=" + tokenPath,
+		"N8N_RUNNERS_CONFIG_PATH=" + configPath,
+		"N8N_RUNNERS_LAUNCHER_LOG_LEVEL=error",
+		"N8N_RUNNERS_TASK_BROKER_URI=http://127.0.0.1:5679",
+	}
+
+	var out bytes.Buffer
+	cmd := NewPrintConfigCommand([]string{"javascript"}, environ, []string{"N8N_RUNNERS_LAUNCHER_LOG_LEVEL"}, &out)
+	require.NoError(t, cmd.Execute())
+
+	output := out.String()
+	for _, secret := range []string{"auth-token-from-file", "hunter2", "api-key-value", "ignored", "db.internal"} {
+		assert.NotContains(t, output, secret)
+	}
+
+	// every line is a source followed by the value, aligned with spaces
+	type sourcedValue struct{ source, value string }
+	var printed []sourcedValue
+	for _, line := range strings.Split(output, "\n") {
+		if source, value, ok := strings.Cut(line, "  "); ok {
+			printed = append(printed, sourcedValue{source, strings.TrimSpace(value)})
+		}
+	}
+
+	for _, expected := range []sourcedValue{
+		{"flag (--log-level)", "N8N_RUNNERS_LAUNCHER_LOG_LEVEL=error"},
+		{"env (N8N_RUNNERS_AUTH_TOKEN_FILE)", "N8N_RUNNERS_AUTH_TOKEN=<redacted>"},
+		{"env", "N8N_RUNNERS_TASK_BROKER_URI=http://127.0.0.1:5679"},
+		{"default", "N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_BACKOFF_MAX_DELAY=1m0s"},
+		{"config file", "runner-type=javascript"},
+		{"default", "name=javascript"},
+		{"default", "health-check-server-port=5681"},
+		{"default", "max-concurrent=1"},
+		{"config file", `args=["start.js"]`},
+		{"env (allowed-env)", "CUSTOM_VAR=custom-value"},
+		{"env (allowed-env)", "CUSTOM_API_KEY=<redacted>"},
+		{"config file (env-overrides)", "NODE_OPTIONS=--max-old-space-size=1024"},
+		{"config file (env-overrides)", "DB_PASSWORD=<redacted>"},
+		{"config file (env-from-files)", "DB_HOST=<redacted>"},
+		{"launcher", "N8N_RUNNERS_TASK_BROKER_URI=http://127.0.0.1:5679"},
+		{"launcher", "N8N_RUNNERS_HEALTH_CHECK_SERVER_PORT=5681"},
+		{"launcher", "N8N_RUNNERS_GRANT_TOKEN=<redacted>"},
+	} {
+		assert.Contains(t, printed, expected, "output:\n%s", output)
+	}
+}
+
+func TestRedactIfSecret(t *testing.T) {
+	tests := []struct {
+		key      string
+		value    string
+		expected string
+	}{
+		{"N8N_RUNNERS_AUTH_TOKEN", "secret", redacted},
+		{"SENTRY_DSN", "https://key@sentry.io/1", redacted},
+		{"db_password", "secret", redacted},
+		{"N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_BACKOFF", "constant", "constant"},
+		{"KEYBOARD_LAYOUT", "us", "us"},
+		{"N8N_RUNNERS_AUTH_TOKEN", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactIfSecret(tt.key, tt.value))
+		})
+	}
+}
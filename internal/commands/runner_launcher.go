@@ -0,0 +1,504 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"task-runner-launcher/internal/config"
+	"task-runner-launcher/internal/env"
+	"task-runner-launcher/internal/errs"
+	"task-runner-launcher/internal/http"
+	"task-runner-launcher/internal/limits"
+	"task-runner-launcher/internal/logs"
+	"task-runner-launcher/internal/metrics"
+	"task-runner-launcher/internal/retry"
+	"task-runner-launcher/internal/ws"
+	"time"
+)
+
+// warmRelaunchDelay is the time to wait before relaunching a `min-idle` runner
+// that exited with an error, to avoid a tight crash loop.
+var warmRelaunchDelay = 5 * time.Second
+
+// operationEnvFromFilesRead is the retried operation of reading the runner's
+// `env-from-files` files on launch.
+const operationEnvFromFilesRead = "env-from-files-read"
+
+// outputCloseDelay is how long to wait, after a runner was killed on shutdown,
+// for its output to be closed, e.g. by processes left behind by the runner.
+const outputCloseDelay = 1 * time.Second
+
+// brokerClient sends the HTTP requests of a runner launch to the task broker.
+type brokerClient interface {
+	CheckUntilBrokerReady(ctx context.Context, logger *logs.Logger) error
+	FetchGrantToken(ctx context.Context, authToken string) (string, error)
+	UserAgent() string
+}
+
+// runnerLauncher runs the launcher lifecycle for a single runner.
+type runnerLauncher struct {
+	logger  *logs.Logger
+	version string // launcher version
+
+	// newBroker and handshake connect to the task broker, replaced in tests
+	newBroker func(cfg http.BrokerClientConfig) brokerClient
+	handshake func(ctx context.Context, cfg ws.HandshakeConfig, logger *logs.Logger) error
+}
+
+func newRunnerLauncher(logger *logs.Logger, version string) *runnerLauncher {
+	return &runnerLauncher{
+		logger:  logger,
+		version: version,
+		newBroker: func(cfg http.BrokerClientConfig) brokerClient {
+			return http.NewBrokerClient(cfg)
+		},
+		handshake: ws.Handshake,
+	}
+}
+
+// runnerLaunch holds everything needed to launch the runner with a name.
+type runnerLaunch struct {
+	runnerName     string
+	baseConfig     *config.BaseConfig
+	launcherConfig *config.LauncherConfig
+	broker         brokerClient
+
+	mu           sync.Mutex
+	runnerConfig *config.RunnerConfig // runner config that `runnerEnv` was prepared for
+	runnerEnv    []string
+}
+
+// current returns the current runner config and the env vars to pass to the
+// runner, preparing the env vars again only if the runner config was reloaded.
+func (l *runnerLaunch) current(logger *logs.Logger) (*config.RunnerConfig, []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	runnerConfig := l.launcherConfig.RunnerConfig(l.runnerName)
+	if runnerConfig != l.runnerConfig {
+		if l.runnerConfig != nil {
+			logger.Info("Applying reloaded runner config")
+		}
+		l.runnerConfig = runnerConfig
+		l.runnerEnv = env.PrepareRunnerEnv(l.baseConfig, runnerConfig, logger)
+	}
+
+	return l.runnerConfig, l.runnerEnv
+}
+
+// run runs the launcher lifecycle for the runner with the given name until the
+// context is cancelled. On cancellation, all running runners are sent SIGTERM
+// and, if still running after the shutdown grace period, SIGKILL.
+//
+// The launcher keeps `min-idle` runners running at all times and, while fewer
+// than `max-concurrent` runners are running, performs the handshake to launch
+// another runner once a task is ready for pickup.
+func (c *runnerLauncher) run(ctx context.Context, launcherConfig *config.LauncherConfig, runnerName string) error {
+	c.logger.Info("Starting launcher goroutine...")
+
+	// 1. prepare env vars to pass to runner
+
+	launch := c.newLaunch(launcherConfig, runnerName)
+	runnerConfig, _ := launch.current(c.logger)
+
+	maxConcurrent := max(runnerConfig.MaxConcurrent, 1)
+
+	// on return, stop any runners still running, then wait for them to exit
+	var runnersWg sync.WaitGroup
+	defer runnersWg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fatalErrs := make(chan error, maxConcurrent)
+
+	// 2. launch `min-idle` runners, each in a reserved slot
+
+	for slot := range runnerConfig.MinIdle {
+		runnersWg.Add(1)
+		go func() {
+			defer runnersWg.Done()
+			if err := c.keepWarm(ctx, launch, slot); err != nil {
+				fatalErrs <- err
+			}
+		}()
+	}
+
+	if runnerConfig.MinIdle > 0 {
+		c.logger.Infof("Keeping %d runner(s) warm", runnerConfig.MinIdle)
+	}
+
+	freeSlots := make(chan int, maxConcurrent)
+	for slot := runnerConfig.MinIdle; slot < maxConcurrent; slot++ {
+		freeSlots <- slot
+	}
+
+	for {
+		// 3. wait for capacity to launch another runner
+
+		var slot int
+		select {
+		case <-ctx.Done():
+			return c.stopped()
+		case err := <-fatalErrs:
+			return err
+		case slot = <-freeSlots:
+		}
+
+		// 4. wait for the runner env to be ready, so that a task is only
+		// accepted once the runner can be launched for it
+
+		runnerConfig, runnerEnv, err := c.prepareRunner(ctx, launch)
+		if err != nil {
+			return c.stopped() // only on cancellation
+		}
+
+		// 5. wait for a task to be ready for pickup
+
+		runnerGrantToken, err := c.waitForTask(ctx, launch)
+		if ctx.Err() != nil {
+			return c.stopped()
+		}
+		if err != nil {
+			return err
+		}
+
+		// 6. launch runner, freeing up its slot once it exits
+
+		c.logger.Debug("Task ready for pickup, launching runner...")
+
+		runnerConfig, runnerEnv = c.refreshRunner(launch, runnerConfig, runnerEnv)
+
+		runnersWg.Add(1)
+		go func() {
+			defer runnersWg.Done()
+			if err := c.runRunner(ctx, launch, slot, runnerConfig, runnerEnv, runnerGrantToken, false); err != nil {
+				fatalErrs <- err
+				return
+			}
+			freeSlots <- slot
+		}()
+	}
+}
+
+// runOnce waits for a single task to be ready for pickup, launches a runner for
+// it, and returns once the runner exits, disregarding `min-idle`.
+func (c *runnerLauncher) runOnce(ctx context.Context, launcherConfig *config.LauncherConfig, runnerName string) error {
+	launch := c.newLaunch(launcherConfig, runnerName)
+
+	runnerConfig, runnerEnv, err := c.prepareRunner(ctx, launch)
+	if err != nil {
+		return c.stopped() // only on cancellation
+	}
+
+	runnerGrantToken, err := c.waitForTask(ctx, launch)
+	if ctx.Err() != nil {
+		return c.stopped()
+	}
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("Task ready for pickup, launching runner...")
+
+	runnerConfig, runnerEnv = c.refreshRunner(launch, runnerConfig, runnerEnv)
+
+	return c.runRunner(ctx, launch, 0, runnerConfig, runnerEnv, runnerGrantToken, false)
+}
+
+// prepareRunner returns the current runner config and the env to pass to the
+// runner, including the `env-from-files` env vars. A file that cannot be read,
+// e.g. while a secret is being rotated, is read again until it can be, with the
+// runner config reloaded in between. Returns an error only on cancellation.
+func (c *runnerLauncher) prepareRunner(ctx context.Context, launch *runnerLaunch) (*config.RunnerConfig, []string, error) {
+	var runnerConfig *config.RunnerConfig
+	runnerEnv, err := retry.UnlimitedRetryWithContext(ctx, operationEnvFromFilesRead, func() ([]string, error) {
+		var baseRunnerEnv []string
+		runnerConfig, baseRunnerEnv = launch.current(c.logger)
+		runnerEnv, err := env.AddEnvFromFiles(baseRunnerEnv, runnerConfig, c.logger)
+		if err != nil {
+			c.logger.Warnf("Failed to prepare runner env, will retry: %v", err)
+		}
+		return runnerEnv, err
+	})
+
+	return runnerConfig, runnerEnv, err
+}
+
+// refreshRunner prepares the runner config and env again once a task is ready
+// for pickup, to pick up a config reload or a secret rotated while waiting. If
+// an `env-from-files` file cannot be read anymore, it returns the given runner
+// config and env instead, as the runner must be launched right away for the
+// task.
+func (c *runnerLauncher) refreshRunner(launch *runnerLaunch, runnerConfig *config.RunnerConfig, runnerEnv []string) (*config.RunnerConfig, []string) {
+	currentConfig, baseRunnerEnv := launch.current(c.logger)
+	currentEnv, err := env.AddEnvFromFiles(baseRunnerEnv, currentConfig, c.logger)
+	if err != nil {
+		c.logger.Warnf("Failed to prepare runner env, launching runner with env prepared before the task: %v", err)
+		return runnerConfig, runnerEnv
+	}
+
+	return currentConfig, currentEnv
+}
+
+// newLaunch returns the launch of the runner with the given name.
+func (c *runnerLauncher) newLaunch(launcherConfig *config.LauncherConfig, runnerName string) *runnerLaunch {
+	baseConfig := launcherConfig.BaseConfig
+
+	return &runnerLaunch{
+		runnerName:     runnerName,
+		baseConfig:     baseConfig,
+		launcherConfig: launcherConfig,
+		broker: c.newBroker(http.BrokerClientConfig{
+			URI:                    baseConfig.TaskBrokerURI,
+			Version:                c.version,
+			RunnerType:             launcherConfig.RunnerConfig(runnerName).RunnerType, // fixed until restart
+			RunnerName:             runnerName,
+			ReadinessCheckTimeout:  baseConfig.ReadinessCheckTimeout,
+			GrantTokenFetchTimeout: baseConfig.GrantTokenFetchTimeout,
+		}),
+	}
+}
+
+// waitForTask performs the handshake with the task broker, reconnecting while
+// the broker is down, until a task is ready for pickup. Returns the grant token
+// for the runner to launch for the task.
+func (c *runnerLauncher) waitForTask(ctx context.Context, launch *runnerLaunch) (string, error) {
+	runnerName := launch.runnerName
+	baseConfig := launch.baseConfig
+
+	for {
+		// 1. check until task broker is ready
+
+		http.SetRunnerState(runnerName, http.StateWaitingForBroker)
+
+		if err := launch.broker.CheckUntilBrokerReady(ctx, c.logger); err != nil {
+			return "", fmt.Errorf("encountered error while waiting for broker to be ready: %w", err)
+		}
+
+		// 2. fetch grant token for launcher
+
+		http.SetRunnerState(runnerName, http.StateHandshaking)
+
+		launcherGrantToken, err := launch.broker.FetchGrantToken(ctx, baseConfig.AuthToken)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch grant token for launcher: %w", err)
+		}
+
+		c.logger.Debug("Fetched grant token for launcher")
+
+		// 3. connect to main and wait for task offer to be accepted
+
+		runnerConfig, _ := launch.current(c.logger)
+		handshakeCfg := ws.HandshakeConfig{
+			TaskType:             runnerConfig.RunnerType, // fixed until restart
+			TaskBrokerServerURI:  baseConfig.TaskBrokerURI,
+			GrantToken:           launcherGrantToken,
+			UserAgent:            launch.broker.UserAgent(),
+			PingInterval:         baseConfig.WsPingInterval,
+			PongTimeout:          baseConfig.WsPongTimeout,
+			OfferRenewalInterval: baseConfig.OfferRenewalInterval,
+		}
+
+		err = c.handshake(ctx, handshakeCfg, c.logger)
+		switch {
+		case ctx.Err() != nil:
+			return "", ctx.Err()
+		case errors.Is(err, errs.ErrServerDown):
+			c.logger.Warn("Task broker is down, launcher will try to reconnect...")
+			metrics.BrokerReconnects.Inc(runnerName)
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Second * 5):
+			}
+			continue // back to checking until broker ready
+		case err != nil:
+			return "", fmt.Errorf("handshake failed: %w", err)
+		}
+
+		metrics.HandshakesCompleted.Inc(runnerName)
+
+		// 4. fetch grant token for runner
+
+		runnerGrantToken, err := launch.broker.FetchGrantToken(ctx, baseConfig.AuthToken)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch grant token for runner: %w", err)
+		}
+
+		c.logger.Debug("Fetched grant token for runner")
+
+		return runnerGrantToken, nil
+	}
+}
+
+// keepWarm keeps a runner running in the given slot until the context is
+// cancelled, relaunching the runner whenever it exits.
+func (c *runnerLauncher) keepWarm(ctx context.Context, launch *runnerLaunch, slot int) error {
+	for {
+		runnerConfig, runnerEnv, err := c.prepareRunner(ctx, launch)
+		if err != nil {
+			return nil // only on cancellation
+		}
+
+		grantToken, err := launch.broker.FetchGrantToken(ctx, launch.baseConfig.AuthToken)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			// broker may be down, so wait for it instead of giving up
+			c.logger.Warnf("Failed to fetch grant token for warm runner in slot %d: %v", slot, err)
+			if err := launch.broker.CheckUntilBrokerReady(ctx, c.logger); err != nil {
+				return nil // only on cancellation
+			}
+			continue
+		}
+
+		c.logger.Debugf("Launching warm runner in slot %d...", slot)
+
+		startTime := time.Now()
+		if err := c.runRunner(ctx, launch, slot, runnerConfig, runnerEnv, grantToken, true); err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// a warm runner is expected to keep running, so avoid a crash loop
+		if time.Since(startTime) < warmRelaunchDelay {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(warmRelaunchDelay):
+			}
+		}
+	}
+}
+
+// launchEnv returns the env for a single runner launch, i.e. the runner env
+// prepared for the runner config plus the launch's port and grant token.
+func launchEnv(baseRunnerEnv []string, healthCheckPort, grantToken string, warm bool) []string {
+	runnerEnv := env.Clear(baseRunnerEnv, env.EnvVarHealthCheckServerPort)
+	runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", env.EnvVarHealthCheckServerPort, healthCheckPort))
+	runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", env.EnvVarGrantToken, grantToken))
+	if warm {
+		runnerEnv = env.Clear(runnerEnv, env.EnvVarAutoShutdownTimeout)
+		runnerEnv = append(runnerEnv, fmt.Sprintf("%s=0", env.EnvVarAutoShutdownTimeout))
+	}
+
+	return runnerEnv
+}
+
+// runRunner launches a runner in the given slot with the given runner config
+// and env, and blocks until it exits. A warm runner is launched with
+// auto-shutdown disabled so that it stays running. Returns an error only if the
+// runner process cannot be started.
+func (c *runnerLauncher) runRunner(ctx context.Context, launch *runnerLaunch, slot int, runnerConfig *config.RunnerConfig, baseRunnerEnv []string, grantToken string, warm bool) error {
+	runnerName := launch.runnerName
+
+	// every slot has its own health check server port
+	basePort, _ := strconv.Atoi(string(runnerConfig.HealthCheckServerPort)) // already validated on config load
+	healthCheckPort := strconv.Itoa(basePort + slot)
+	runnerServerURI := fmt.Sprintf("http://%s:%s", launch.baseConfig.RunnerHealthCheckServerHost, healthCheckPort)
+
+	runnerEnv := launchEnv(baseRunnerEnv, healthCheckPort, grantToken, warm)
+
+	c.logger.Debugf("Slot: %d", slot)
+	c.logger.Debugf("Working directory: %s", runnerConfig.WorkDir)
+	c.logger.Debugf("Command: %s", runnerConfig.Command)
+	c.logger.Debugf("Args: %v", runnerConfig.Args)
+
+	healthCtx, cancelHealthMonitor := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	// on shutdown, forward SIGTERM to runner, and SIGKILL it if still running
+	// after grace period, or right away without grace period. The runner runs in
+	// its own process group, so that any processes it spawned are signaled too.
+	shutdownGracePeriod := time.Duration(launch.baseConfig.ShutdownGracePeriod) * time.Second
+	cmd := exec.CommandContext(ctx, runnerConfig.Command, runnerConfig.Args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var killedOnShutdown atomic.Bool // whether the launcher killed the runner
+	killOnShutdown := func() {
+		if syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) == nil {
+			killedOnShutdown.Store(true)
+		}
+	}
+	cmd.Cancel = func() error {
+		if shutdownGracePeriod == 0 {
+			c.logger.Info("Killing runner, as there is no shutdown grace period")
+			killOnShutdown()
+			return nil
+		}
+		c.logger.Infof("Forwarding SIGTERM to runner, waiting up to %v for it to exit...", shutdownGracePeriod)
+		time.AfterFunc(shutdownGracePeriod, killOnShutdown)
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = shutdownGracePeriod + outputCloseDelay
+	cmd.Dir = runnerConfig.WorkDir
+	cmd.Env = runnerEnv
+	logLevel := logs.ParseLevel(launch.baseConfig.LogLevel)
+	cmd.Stdout, cmd.Stderr = logs.GetRunnerWriters(logLevel, runnerConfig.RunnerType, runnerName, c.logger.LauncherID())
+
+	limiter, err := limits.Apply(cmd, fmt.Sprintf("runner-%s-%d", runnerName, slot), runnerConfig.Limits, c.logger)
+	if err != nil {
+		cancelHealthMonitor()
+		return fmt.Errorf("failed to apply resource limits to runner: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		limiter.Exited()
+		cancelHealthMonitor()
+		return fmt.Errorf("failed to start runner process: %w", err)
+	}
+
+	limiter.Started()
+
+	metrics.RunnerStarted(runnerName, runnerConfig.RunnerType, slot)
+	http.SetSlotState(runnerName, slot, http.StateRunnerRunning)
+	defer http.ClearSlotState(runnerName, slot)
+
+	http.ManageRunnerHealth(healthCtx, cmd, runnerServerURI, runnerName, slot, &wg, c.logger)
+
+	err = cmd.Wait()
+	exceededLimit := limiter.Exited()
+	switch {
+	case ctx.Err() != nil:
+		if killedOnShutdown.Load() {
+			c.logger.Warn("Runner did not exit within grace period and was killed")
+		} else {
+			c.logger.Info("Runner process exited on shutdown")
+		}
+		metrics.RunnerExited(runnerName, runnerConfig.RunnerType, slot, metrics.ExitReasonShutdown)
+	case err != nil && exceededLimit == limits.ExceededMemory:
+		c.logger.Warnf("Runner process was killed by the OOM killer on reaching %s", exceededLimit)
+		metrics.RunnerExited(runnerName, runnerConfig.RunnerType, slot, metrics.ExitReasonOOMKilled)
+	case err != nil && exceededLimit != "":
+		c.logger.Warnf("Runner process exited with error after reaching %s: %v", exceededLimit, err)
+		metrics.RunnerExited(runnerName, runnerConfig.RunnerType, slot, metrics.ExitReasonLimitExceeded)
+	case err != nil && err.Error() == "signal: killed":
+		c.logger.Warn("Unresponsive runner process was terminated")
+		metrics.RunnerExited(runnerName, runnerConfig.RunnerType, slot, metrics.ExitReasonUnresponsive)
+	case err != nil:
+		c.logger.Errorf("Runner process exited with error: %v", err)
+		metrics.RunnerExited(runnerName, runnerConfig.RunnerType, slot, metrics.ExitReasonError)
+	default:
+		c.logger.Info("Runner process exited on idle timeout")
+		metrics.RunnerExited(runnerName, runnerConfig.RunnerType, slot, metrics.ExitReasonIdle)
+	}
+	cancelHealthMonitor()
+
+	wg.Wait()
+
+	return nil
+}
+
+// stopped logs that the launcher goroutine stopped due to shutdown.
+func (c *runnerLauncher) stopped() error {
+	c.logger.Info("Stopped launcher goroutine on shutdown")
+	return nil
+}
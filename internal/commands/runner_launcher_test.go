@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"task-runner-launcher/internal/config"
+	"task-runner-launcher/internal/http"
+	"task-runner-launcher/internal/logs"
+	"task-runner-launcher/internal/ws"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker is a task broker that is always ready and hands out a task, i.e.
+// completes a handshake, per value sent on `tasks`.
+type fakeBroker struct {
+	tasks       chan struct{}
+	grantTokens atomic.Int32
+}
+
+func (b *fakeBroker) CheckUntilBrokerReady(_ context.Context, _ *logs.Logger) error {
+	return nil
+}
+
+func (b *fakeBroker) FetchGrantToken(_ context.Context, _ string) (string, error) {
+	return fmt.Sprintf("grant-token-%d", b.grantTokens.Add(1)), nil
+}
+
+func (b *fakeBroker) UserAgent() string {
+	return "test"
+}
+
+func (b *fakeBroker) handshake(ctx context.Context, _ ws.HandshakeConfig, _ *logs.Logger) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.tasks:
+		return nil
+	}
+}
+
+// newTestLauncher returns a runner launcher connected to the fake broker, and
+// a launcher config for a runner named `test` that runs the given command.
+func newTestLauncher(t *testing.T, broker *fakeBroker, runnerConfig *config.RunnerConfig) (*runnerLauncher, *config.LauncherConfig) {
+	t.Helper()
+
+	launcher := newRunnerLauncher(logs.NewLogger(logs.ErrorLevel, ""), "1.2.3")
+	launcher.newBroker = func(_ http.BrokerClientConfig) brokerClient { return broker }
+	launcher.handshake = broker.handshake
+
+	runnerConfig.RunnerType = "javascript"
+	runnerConfig.WorkDir = t.TempDir()
+	runnerConfig.HealthCheckServerPort = "5681"
+
+	launcherConfig := &config.LauncherConfig{
+		BaseConfig: &config.BaseConfig{
+			LogLevel:                    "error",
+			TaskBrokerURI:               "http://127.0.0.1:5679",
+			AutoShutdownTimeout:         "15",
+			TaskTimeout:                 "60",
+			RunnerHealthCheckServerHost: "127.0.0.1",
+			ShutdownGracePeriod:         0,
+		},
+		RunnerConfigs: map[string]*config.RunnerConfig{"test": runnerConfig},
+	}
+
+	return launcher, launcherConfig
+}
+
+// launchesOf returns the health check port and auto-shutdown timeout that each
+// runner was launched with, as recorded by the runner command.
+func launchesOf(t *testing.T, workDir string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(workDir, "launches"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestRunnerLauncherRun(t *testing.T) {
+	defer func(delay time.Duration) { warmRelaunchDelay = delay }(warmRelaunchDelay)
+	warmRelaunchDelay = 10 * time.Millisecond
+
+	const recordLaunch = `echo "$N8N_RUNNERS_HEALTH_CHECK_SERVER_PORT $N8N_RUNNERS_AUTO_SHUTDOWN_TIMEOUT" >> launches; `
+
+	tests := []struct {
+		name              string
+		minIdle           int
+		maxConcurrent     int
+		script            string
+		tasks             int
+		expectedLaunches  []string
+		expectedUnclaimed int
+	}{
+		{
+			name:             "launches runner per task in own slot",
+			maxConcurrent:    2,
+			script:           recordLaunch + "exec sleep 30",
+			tasks:            2,
+			expectedLaunches: []string{"5681 15", "5682 15"},
+		},
+		{
+			name:              "launches no more runners than max-concurrent",
+			maxConcurrent:     2,
+			script:            recordLaunch + "exec sleep 30",
+			tasks:             3,
+			expectedLaunches:  []string{"5681 15", "5682 15"},
+			expectedUnclaimed: 1,
+		},
+		{
+			name:             "reuses slot of exited runner",
+			maxConcurrent:    1,
+			script:           recordLaunch + "exit 0",
+			tasks:            2,
+			expectedLaunches: []string{"5681 15", "5681 15"},
+		},
+		{
+			name:             "keeps min-idle runners warm without auto-shutdown",
+			minIdle:          2,
+			maxConcurrent:    2,
+			script:           recordLaunch + "exec sleep 30",
+			expectedLaunches: []string{"5681 0", "5682 0"},
+		},
+		{
+			name:             "relaunches warm runner after exit",
+			minIdle:          1,
+			maxConcurrent:    1,
+			script:           recordLaunch + "exit 0",
+			expectedLaunches: []string{"5681 0", "5681 0", "5681 0"},
+		},
+		{
+			name:             "launches runner per task next to warm runners",
+			minIdle:          1,
+			maxConcurrent:    2,
+			script:           recordLaunch + "exec sleep 30",
+			tasks:            1,
+			expectedLaunches: []string{"5681 0", "5682 15"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &fakeBroker{tasks: make(chan struct{}, tt.tasks)}
+			for range tt.tasks {
+				broker.tasks <- struct{}{}
+			}
+
+			launcher, launcherConfig := newTestLauncher(t, broker, &config.RunnerConfig{
+				Command:       "/bin/sh",
+				Args:          []string{"-c", tt.script},
+				MinIdle:       tt.minIdle,
+				MaxConcurrent: tt.maxConcurrent,
+			})
+			workDir := launcherConfig.RunnerConfigs["test"].WorkDir
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- launcher.run(ctx, launcherConfig, "test") }()
+
+			require.Eventually(t, func() bool {
+				return len(launchesOf(t, workDir)) >= len(tt.expectedLaunches)
+			}, 5*time.Second, 10*time.Millisecond)
+
+			assert.Len(t, broker.tasks, tt.expectedUnclaimed)
+
+			cancel()
+			require.NoError(t, <-done)
+
+			launches := launchesOf(t, workDir)[:len(tt.expectedLaunches)]
+			assert.ElementsMatch(t, tt.expectedLaunches, launches)
+		})
+	}
+}
+
+func TestRunnerLauncherRunFatalError(t *testing.T) {
+	tests := []struct {
+		name    string
+		minIdle int
+		tasks   int
+	}{
+		{
+			name:  "runner launched for task",
+			tasks: 1,
+		},
+		{
+			name:    "warm runner",
+			minIdle: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &fakeBroker{tasks: make(chan struct{}, tt.tasks)}
+			for range tt.tasks {
+				broker.tasks <- struct{}{}
+			}
+
+			launcher, launcherConfig := newTestLauncher(t, broker, &config.RunnerConfig{
+				Command:       "/nonexistent/runner",
+				MinIdle:       tt.minIdle,
+				MaxConcurrent: 1,
+			})
+
+			done := make(chan error, 1)
+			go func() { done <- launcher.run(context.Background(), launcherConfig, "test") }()
+
+			select {
+			case err := <-done:
+				assert.ErrorContains(t, err, "failed to start runner process")
+			case <-time.After(5 * time.Second):
+				t.Fatal("launcher did not stop on fatal error")
+			}
+		})
+	}
+}
+
+func TestRunnerLauncherRunStopsRunnerProcessGroup(t *testing.T) {
+	tests := []struct {
+		name                string
+		shutdownGracePeriod int
+	}{
+		{
+			name: "killed without grace period",
+		},
+		{
+			name:                "terminated within grace period",
+			shutdownGracePeriod: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &fakeBroker{tasks: make(chan struct{}, 1)}
+			broker.tasks <- struct{}{}
+
+			launcher, launcherConfig := newTestLauncher(t, broker, &config.RunnerConfig{
+				Command: "/bin/sh",
+				Args:    []string{"-c", "sleep 30 & echo $! > child; wait"},
+			})
+			launcherConfig.BaseConfig.ShutdownGracePeriod = tt.shutdownGracePeriod
+			childPath := filepath.Join(launcherConfig.RunnerConfigs["test"].WorkDir, "child")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- launcher.run(ctx, launcherConfig, "test") }()
+
+			var childPid int
+			require.Eventually(t, func() bool {
+				content, err := os.ReadFile(childPath)
+				if err != nil {
+					return false
+				}
+				childPid, err = strconv.Atoi(strings.TrimSpace(string(content)))
+				return err == nil
+			}, 5*time.Second, 10*time.Millisecond)
+
+			cancel()
+			require.NoError(t, <-done)
+
+			assert.Eventually(t, func() bool {
+				// the exited child may linger as a zombie until reaped by init
+				stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", childPid))
+				return err != nil || strings.Contains(string(stat), ") Z ")
+			}, 5*time.Second, 10*time.Millisecond, "process spawned by runner is still running")
+		})
+	}
+}
+
+func TestLaunchEnv(t *testing.T) {
+	baseRunnerEnv := []string{
+		"PATH=/usr/bin",
+		"N8N_RUNNERS_HEALTH_CHECK_SERVER_PORT=5681",
+		"N8N_RUNNERS_AUTO_SHUTDOWN_TIMEOUT=15",
+	}
+
+	tests := []struct {
+		name     string
+		warm     bool
+		expected []string
+	}{
+		{
+			name: "runner for task keeps auto-shutdown timeout",
+			expected: []string{
+				"PATH=/usr/bin",
+				"N8N_RUNNERS_AUTO_SHUTDOWN_TIMEOUT=15",
+				"N8N_RUNNERS_HEALTH_CHECK_SERVER_PORT=5682",
+				"N8N_RUNNERS_GRANT_TOKEN=token",
+			},
+		},
+		{
+			name: "warm runner has auto-shutdown disabled",
+			warm: true,
+			expected: []string{
+				"PATH=/usr/bin",
+				"N8N_RUNNERS_HEALTH_CHECK_SERVER_PORT=5682",
+				"N8N_RUNNERS_GRANT_TOKEN=token",
+				"N8N_RUNNERS_AUTO_SHUTDOWN_TIMEOUT=0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, launchEnv(baseRunnerEnv, "5682", "token", tt.warm))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"io"
+	"task-runner-launcher/internal/config"
+)
+
+// SchemaCommand prints the JSON Schema of the runners config file.
+type SchemaCommand struct {
+	out io.Writer
+}
+
+func NewSchemaCommand(out io.Writer) *SchemaCommand {
+	return &SchemaCommand{out: out}
+}
+
+func (c *SchemaCommand) Execute() error {
+	_, err := c.out.Write(config.RunnersSchema())
+	return err
+}
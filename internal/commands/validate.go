@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"task-runner-launcher/internal/config"
+	"task-runner-launcher/internal/env"
+	"text/tabwriter"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+const (
+	statusOK   = "OK"
+	statusWarn = "WARN"
+	statusFail = "FAIL"
+)
+
+// ValidateCommand checks the launcher's config file and environment for the
+// given runners without launching anything, and prints a report of every check.
+type ValidateCommand struct {
+	runnerNames []string
+	environ     []string // in the form of `os.Environ()`
+	out         io.Writer
+
+	report   *tabwriter.Writer
+	failures int
+}
+
+func NewValidateCommand(runnerNames []string, environ []string, out io.Writer) *ValidateCommand {
+	return &ValidateCommand{runnerNames: runnerNames, environ: environ, out: out}
+}
+
+// Execute runs all checks and returns an error if any check failed.
+func (c *ValidateCommand) Execute() error {
+	c.report = tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	c.failures = 0
+
+	envVars := envMap(c.environ)
+
+	lookuper := &recordingLookuper{Lookuper: envconfig.MapLookuper(envVars)}
+	launcherConfig, err := config.LoadLauncherConfig(c.runnerNames, lookuper)
+
+	c.checkSecretFiles(lookuper.found)
+
+	if err != nil {
+		for _, err := range unwrapJoined(err) {
+			c.result(statusFail, "config", err.Error())
+		}
+	} else {
+		c.result(statusOK, "config", launcherConfig.BaseConfig.ConfigPath)
+		c.checkPort("launcher.health-check-port", "", launcherConfig.BaseConfig.HealthCheckServerPort)
+		for _, runnerName := range c.runnerNames {
+			c.checkRunner(launcherConfig, runnerName, envVars)
+		}
+	}
+
+	if err := c.report.Flush(); err != nil {
+		return err
+	}
+
+	if c.failures > 0 {
+		return fmt.Errorf("validation failed with %d failed check(s)", c.failures)
+	}
+
+	fmt.Fprintln(c.out, "Validation passed")
+
+	return nil
+}
+
+// checkSecretFiles checks that every file referenced by a `_FILE` env var read
+// by the launcher is readable, as the launcher would otherwise treat the env var
+// as unset.
+func (c *ValidateCommand) checkSecretFiles(envVars map[string]string) {
+	var fileKeys []string
+	for key := range envVars {
+		if strings.HasSuffix(key, "_FILE") {
+			fileKeys = append(fileKeys, key)
+		}
+	}
+	sort.Strings(fileKeys) // ensure consistent order
+
+	for _, key := range fileKeys {
+		// #nosec G304 -- path is controlled by system administrator via environment variable
+		if _, err := os.ReadFile(envVars[key]); err != nil {
+			c.result(statusFail, "env."+key, err.Error())
+		} else {
+			c.result(statusOK, "env."+key, envVars[key])
+		}
+	}
+}
+
+// checkRunner checks the runner's command, ports, allowed env vars and env var
+// files. The workdir is already checked on config load.
+func (c *ValidateCommand) checkRunner(launcherConfig *config.LauncherConfig, runnerName string, envVars map[string]string) {
+	runnerConfig := launcherConfig.RunnerConfig(runnerName)
+	field := func(name string) string { return runnerName + "." + name }
+
+	c.result(statusOK, field("workdir"), runnerConfig.WorkDir)
+
+	if path, err := resolveCommand(runnerConfig.Command, runnerConfig.WorkDir); err != nil {
+		c.result(statusFail, field("command"), err.Error())
+	} else {
+		c.result(statusOK, field("command"), path)
+	}
+
+	basePort, _ := strconv.Atoi(string(runnerConfig.HealthCheckServerPort)) // already validated on config load
+	for slot := range runnerConfig.MaxConcurrent {
+		c.checkPort(field("health-check-server-port"), launcherConfig.BaseConfig.RunnerHealthCheckServerHost, strconv.Itoa(basePort+slot))
+	}
+
+	var missing []string
+	for _, pattern := range runnerConfig.AllowedEnv {
+		if !matchesAny(pattern, envVars) {
+			missing = append(missing, pattern)
+		}
+	}
+
+	passed := 0
+	for key := range envVars {
+		_, allowed := env.MatchingPattern(runnerConfig.AllowedEnv, key)
+		_, denied := env.MatchingPattern(runnerConfig.DeniedEnv, key)
+		if allowed && !denied {
+			passed++
+		}
+	}
+
+	if len(missing) > 0 {
+		c.result(statusWarn, field("allowed-env"), "not set in environment: "+strings.Join(missing, ", "))
+	} else {
+		c.result(statusOK, field("allowed-env"), fmt.Sprintf("%d env var(s) set", passed))
+	}
+
+	fileKeys := make([]string, 0, len(runnerConfig.EnvFromFiles))
+	for key := range runnerConfig.EnvFromFiles {
+		fileKeys = append(fileKeys, key)
+	}
+	sort.Strings(fileKeys) // ensure consistent order
+
+	// files are read on every launch, so a file unreadable now would fail the launch
+	for _, key := range fileKeys {
+		filePath := runnerConfig.EnvFromFiles[key]
+		// #nosec G304 -- path is controlled by system administrator via config file
+		if _, err := os.ReadFile(filePath); err != nil {
+			c.result(statusFail, field("env-from-files."+key), err.Error())
+		} else {
+			c.result(statusOK, field("env-from-files."+key), filePath)
+		}
+	}
+}
+
+// matchesAny returns whether the `allowed-env` pattern matches any of the env vars.
+func matchesAny(pattern string, envVars map[string]string) bool {
+	for key := range envVars {
+		if _, ok := env.MatchingPattern([]string{pattern}, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkPort checks that the port is free to listen on at the given host.
+func (c *ValidateCommand) checkPort(field, host, port string) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		c.result(statusFail, field, fmt.Sprintf("port %s is unavailable: %v", port, err))
+		return
+	}
+	listener.Close()
+
+	c.result(statusOK, field, "port "+port+" is free")
+}
+
+func (c *ValidateCommand) result(status, field, detail string) {
+	if status == statusFail {
+		c.failures++
+	}
+
+	fmt.Fprintf(c.report, "%s\t%s\t%s\n", status, field, detail)
+}
+
+// resolveCommand returns the path to the executable that the runner's command
+// resolves to, like `exec.Cmd` does with the runner's working dir.
+func resolveCommand(command, workDir string) (string, error) {
+	if !strings.Contains(command, string(filepath.Separator)) {
+		return exec.LookPath(command)
+	}
+
+	path := command
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+		return "", fmt.Errorf("%s is not an executable file", path)
+	}
+
+	return path, nil
+}
+
+// unwrapJoined returns the errors joined by `errors.Join`, including those
+// joined within them, or the error itself.
+func unwrapJoined(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, unwrapJoined(err)...)
+	}
+
+	return errs
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCommand(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "runner.sh"), []byte("#!/bin/sh\n"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "not-executable.sh"), []byte("#!/bin/sh\n"), 0o600))
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfig := func(command, port string) {
+		content := `{
+			"task-runners": [{
+				"runner-type": "javascript",
+				"workdir": "` + workDir + `",
+				"command": "` + command + `",
+				"args": [],
+				"health-check-server-port": "` + port + `",
+				"allowed-env": ["PATH", "MISSING_VAR", "MISSING_PREFIX_*"]
+			}]
+		}`
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+	}
+
+	baseEnviron := []string{
+		"N8N_RUNNERS_AUTH_TOKEN=test-token",
+		"N8N_RUNNERS_TASK_BROKER_URI=http://127.0.0.1:5679",
+		"N8N_RUNNERS_CONFIG_PATH=" + configPath,
+		"N8N_RUNNERS_LAUNCHER_HEALTH_CHECK_PORT=" + freePort(t),
+		"PATH=/usr/bin",
+	}
+
+	busyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busyListener.Close()
+	_, busyPort, _ := net.SplitHostPort(busyListener.Addr().String())
+
+	tests := []struct {
+		name          string
+		command       string
+		port          string
+		extraEnviron  []string
+		runnerNames   []string
+		expectedError bool
+		expectedLines []string
+	}{
+		{
+			name:        "valid config",
+			command:     "./runner.sh",
+			port:        freePort(t),
+			runnerNames: []string{"javascript"},
+			expectedLines: []string{
+				"OK    config",
+				"OK    javascript.command",
+				"OK    javascript.health-check-server-port",
+				"WARN  javascript.allowed-env",
+				"not set in environment: MISSING_VAR, MISSING_PREFIX_*",
+				"Validation passed",
+			},
+		},
+		{
+			name:          "command not executable",
+			command:       "./not-executable.sh",
+			port:          freePort(t),
+			runnerNames:   []string{"javascript"},
+			expectedError: true,
+			expectedLines: []string{"FAIL  javascript.command", "not-executable.sh is not an executable file"},
+		},
+		{
+			name:          "command not found in PATH",
+			command:       "definitely-not-a-command",
+			port:          freePort(t),
+			runnerNames:   []string{"javascript"},
+			expectedError: true,
+			expectedLines: []string{"FAIL  javascript.command"},
+		},
+		{
+			name:          "port in use",
+			command:       "./runner.sh",
+			port:          busyPort,
+			runnerNames:   []string{"javascript"},
+			expectedError: true,
+			expectedLines: []string{"FAIL  javascript.health-check-server-port", "port " + busyPort + " is unavailable"},
+		},
+		{
+			name:          "unreadable secret file",
+			command:       "./runner.sh",
+			port:          freePort(t),
+			extraEnviron:  []string{"N8N_RUNNERS_AUTH_TOKEN_FILE=/nonexistent/token"},
+			runnerNames:   []string{"javascript"},
+			expectedError: true,
+			expectedLines: []string{"FAIL  env.N8N_RUNNERS_AUTH_TOKEN_FILE"},
+		},
+		{
+			name:          "runner not in config file",
+			command:       "./runner.sh",
+			port:          freePort(t),
+			runnerNames:   []string{"python"},
+			expectedError: true,
+			expectedLines: []string{"FAIL  config", "does not contain requested runner: python"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(tt.command, tt.port)
+
+			var out bytes.Buffer
+			environ := append(append([]string{}, baseEnviron...), tt.extraEnviron...)
+			err := NewValidateCommand(tt.runnerNames, environ, &out).Execute()
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.NotContains(t, out.String(), "Validation passed")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			for _, line := range tt.expectedLines {
+				assert.Contains(t, out.String(), line)
+			}
+		})
+	}
+}
+
+// freePort returns a port that is free to listen on at the time of the call.
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	return port
+}
@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+)
+
+// VersionCommand prints the launcher version.
+type VersionCommand struct {
+	version string
+	out     io.Writer
+}
+
+func NewVersionCommand(version string, out io.Writer) *VersionCommand {
+	return &VersionCommand{version: version, out: out}
+}
+
+func (c *VersionCommand) Execute() error {
+	_, err := fmt.Fprintf(c.out, "task-runner-launcher %s\n", c.version)
+	return err
+}
@@ -2,15 +2,28 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"task-runner-launcher/internal/errs"
+	"task-runner-launcher/internal/limits"
 	"task-runner-launcher/internal/logs"
+	"task-runner-launcher/internal/retry"
+	"task-runner-launcher/internal/tlsconfig"
+	"time"
 
 	"github.com/sethvargo/go-envconfig"
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -20,8 +33,28 @@ const (
 
 // LauncherConfig holds the full configuration for the launcher.
 type LauncherConfig struct {
-	BaseConfig    *BaseConfig
+	BaseConfig *BaseConfig
+
+	// BrokerTLSConfig is the TLS config for connections to the task broker,
+	// built from `BaseConfig.BrokerTLS` on load, or nil for the defaults.
+	BrokerTLSConfig *tls.Config
+
+	// RunnerConfigs holds the config per runner name. After startup, use
+	// `RunnerConfig` to read it, as runner configs may be reloaded.
 	RunnerConfigs map[string]*RunnerConfig
+
+	// lookuper resolves env vars in runner config values on reload.
+	lookuper envconfig.Lookuper
+
+	mu sync.RWMutex
+}
+
+// RunnerConfig returns the current config for the runner with the given name.
+func (c *LauncherConfig) RunnerConfig(runnerName string) *RunnerConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.RunnerConfigs[runnerName]
 }
 
 // BaseConfig holds the configuration for the launcher, excluding runner configs.
@@ -29,6 +62,10 @@ type BaseConfig struct {
 	// LogLevel is the log level for the launcher. Default: `info`.
 	LogLevel string `env:"N8N_RUNNERS_LAUNCHER_LOG_LEVEL, default=info"`
 
+	// LogFormat is the log output format for the launcher and runners: `text`
+	// or `json`. Default: `text`.
+	LogFormat string `env:"N8N_RUNNERS_LAUNCHER_LOG_FORMAT, default=text"`
+
 	// AuthToken is the auth token sent by the launcher to the task broker in
 	// exchange for a single-use grant token, later passed to the runner.
 	AuthToken string `env:"N8N_RUNNERS_AUTH_TOKEN, required"`
@@ -44,15 +81,56 @@ type BaseConfig struct {
 	// TaskBrokerURI is the URI of the task broker server.
 	TaskBrokerURI string `env:"N8N_RUNNERS_TASK_BROKER_URI, default=http://127.0.0.1:5679"`
 
+	// RunnerBrokerURI is the URI of the task broker server for runners to
+	// connect to, if other than TaskBrokerURI. Required if TaskBrokerURI is a
+	// `unix://` URI, as runners can only connect over TCP.
+	RunnerBrokerURI string `env:"N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI"`
+
 	// HealthCheckServerPort is the port for the launcher's health check server.
 	HealthCheckServerPort string `env:"N8N_RUNNERS_LAUNCHER_HEALTH_CHECK_PORT, default=5680"`
 
 	// RunnerHealthCheckServerHost is the host for all runners' health check servers.
 	RunnerHealthCheckServerHost string `env:"N8N_RUNNERS_HEALTH_CHECK_SERVER_HOST, default=127.0.0.1"`
 
+	// ShutdownGracePeriod is how long (in seconds) the launcher waits for runners
+	// to exit after forwarding SIGTERM to them, before sending SIGKILL. If 0,
+	// runners are sent SIGKILL right away.
+	ShutdownGracePeriod int `env:"N8N_RUNNERS_LAUNCHER_SHUTDOWN_GRACE_PERIOD, default=10"`
+
 	// ConfigPath is the path to the runners config file. Default: `/etc/n8n-task-runners.json`.
 	ConfigPath string `env:"N8N_RUNNERS_CONFIG_PATH, default=/etc/n8n-task-runners.json"`
 
+	// ReadinessCheckBackoff is the backoff between retries of the task broker
+	// readiness check.
+	ReadinessCheckBackoff *BackoffConfig `env:", prefix=N8N_RUNNERS_LAUNCHER_READINESS_CHECK_"`
+
+	// GrantTokenFetchBackoff is the backoff between retries of grant token fetches.
+	GrantTokenFetchBackoff *BackoffConfig `env:", prefix=N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_"`
+
+	// ReadinessCheckTimeout is the timeout of a single task broker readiness
+	// check request.
+	ReadinessCheckTimeout time.Duration `env:"N8N_RUNNERS_LAUNCHER_READINESS_CHECK_TIMEOUT, default=5s"`
+
+	// GrantTokenFetchTimeout is the timeout of a single grant token request.
+	GrantTokenFetchTimeout time.Duration `env:"N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_TIMEOUT, default=10s"`
+
+	// WsPingInterval is the interval between pings sent to the task broker over
+	// the websocket connection while waiting for a task offer to be accepted.
+	WsPingInterval time.Duration `env:"N8N_RUNNERS_LAUNCHER_WS_PING_INTERVAL, default=30s"`
+
+	// WsPongTimeout is how long to wait for the pong to a ping before
+	// considering the websocket connection dropped.
+	WsPongTimeout time.Duration `env:"N8N_RUNNERS_LAUNCHER_WS_PONG_TIMEOUT, default=10s"`
+
+	// OfferRenewalInterval is how long each of the launcher's expiring task
+	// offers is valid for, renewed once expired. Default: `0`, i.e. a single
+	// non-expiring offer.
+	OfferRenewalInterval time.Duration `env:"N8N_RUNNERS_LAUNCHER_OFFER_RENEWAL_INTERVAL, default=0s"`
+
+	// BrokerTLS is the TLS config for connections to the task broker over
+	// `https` and `wss`.
+	BrokerTLS *BrokerTLSConfig `env:", prefix=N8N_RUNNERS_TASK_BROKER_TLS_"`
+
 	// Sentry is the Sentry config for the launcher, a subset of what is defined in:
 	// https://docs.sentry.io/platforms/go/configuration/options/
 	Sentry *SentryConfig
@@ -66,51 +144,168 @@ type SentryConfig struct {
 	DeploymentName string `env:"DEPLOYMENT_NAME, default=unknown"`
 }
 
+// BackoffConfig holds the retry backoff configuration for a single operation.
+type BackoffConfig struct {
+	// Strategy is the backoff strategy: `constant`, `exponential` or `decorrelated-jitter`.
+	Strategy string `env:"BACKOFF, default=constant"`
+
+	// BaseDelay is the wait after the first failed attempt, and the constant wait
+	// for the `constant` strategy.
+	BaseDelay time.Duration `env:"BACKOFF_BASE_DELAY, default=5s"`
+
+	// MaxDelay is the cap on the wait between retries.
+	MaxDelay time.Duration `env:"BACKOFF_MAX_DELAY, default=60s"`
+}
+
+// BrokerTLSConfig holds the TLS configuration for connections to the task broker.
+type BrokerTLSConfig struct {
+	// CAFile is the path to a PEM file of CA certs to trust in addition to the
+	// system CA certs, e.g. for a broker with a cert signed by a private CA.
+	CAFile string `env:"CA_FILE"`
+
+	// CertFile is the path to the PEM client cert to present for mTLS.
+	CertFile string `env:"CERT_FILE"`
+
+	// KeyFile is the path to the PEM private key of the client cert.
+	KeyFile string `env:"KEY_FILE"`
+
+	// ServerName is the name to verify the broker's cert against, if other than
+	// the host of the task broker URI.
+	ServerName string `env:"SERVER_NAME"`
+}
+
 // RunnerConfig holds the configuration for a single task runner.
 type RunnerConfig struct {
 	// Type of task runner, e.g. "javascript" or "python".
-	RunnerType string `json:"runner-type"`
+	RunnerType string `json:"runner-type" yaml:"runner-type" toml:"runner-type"`
+
+	// Name of the runner, used to select the runner on the command line and to
+	// tell apart runners of the same type. Defaults to the runner type. Must be
+	// unique across runners, and only contain letters, digits, "_", "." and "-".
+	Name string `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
 
-	// Path to dir containing the runner binary.
-	WorkDir string `json:"workdir"`
+	// Path to dir containing the runner binary, used as the runner's working dir.
+	WorkDir string `json:"workdir" yaml:"workdir" toml:"workdir"`
 
 	// Command to start runner.
-	Command string `json:"command"`
+	Command string `json:"command" yaml:"command" toml:"command"`
 
 	// Arguments for command, currently path to runner entrypoint.
-	Args []string `json:"args"`
+	Args []string `json:"args" yaml:"args" toml:"args"`
 
 	// Port for the runner's health check server.
 	// When a single runner is configured, this is optional and defaults to 5681.
 	// When multiple runners are configured, this is required and must be unique per runner.
-	HealthCheckServerPort string `json:"health-check-server-port,omitempty"`
+	HealthCheckServerPort Port `json:"health-check-server-port,omitempty" yaml:"health-check-server-port,omitempty" toml:"health-check-server-port,omitempty"`
+
+	// Number of runners to keep running at all times, launched without waiting
+	// for a task and relaunched on exit. Defaults to 0.
+	MinIdle int `json:"min-idle,omitempty" yaml:"min-idle,omitempty" toml:"min-idle,omitempty"`
+
+	// Max number of runners to run at the same time, including `min-idle` runners.
+	// Runner `n` (starting at 0) uses `health-check-server-port + n`. Defaults to 1.
+	MaxConcurrent int `json:"max-concurrent,omitempty" yaml:"max-concurrent,omitempty" toml:"max-concurrent,omitempty"`
 
-	// Env vars for the launcher to pass from its own environment to the runner.
-	AllowedEnv []string `json:"allowed-env"`
+	// Resource limits for every runner process, unlimited if unset.
+	Limits *limits.Spec `json:"limits,omitempty" yaml:"limits,omitempty" toml:"limits,omitempty"`
+
+	// Env vars for the launcher to pass from its own environment to the runner,
+	// by name or by glob pattern, e.g. "OTEL_*".
+	AllowedEnv []string `json:"allowed-env" yaml:"allowed-env" toml:"allowed-env"`
+
+	// Env vars for the launcher never to pass from its own environment to the
+	// runner, by name or by glob pattern, even if they match `allowed-env`.
+	DeniedEnv []string `json:"denied-env,omitempty" yaml:"denied-env,omitempty" toml:"denied-env,omitempty"`
 
 	// Env vars for the launcher to set directly on the runner.
-	EnvOverrides map[string]string `json:"env-overrides"`
+	EnvOverrides map[string]string `json:"env-overrides" yaml:"env-overrides" toml:"env-overrides"`
+
+	// Env vars for the launcher to set on the runner to the contents of files,
+	// by env var name. Every file is read anew on every launch, so that rotated
+	// secrets are picked up.
+	EnvFromFiles map[string]string `json:"env-from-files,omitempty" yaml:"env-from-files,omitempty" toml:"env-from-files,omitempty"`
+
+	// defaulted holds the JSON names of the fields set to their default value on
+	// load because they were missing from the config file.
+	defaulted []string
+}
+
+// Port is a port number, unmarshaled from a number or from a string so that
+// it can be given unquoted in every config file format.
+type Port string
+
+// UnmarshalJSON parses a port given as a number or as a string in JSON config
+// files. The port number is validated on config load.
+func (p *Port) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*p = Port(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid port %s, expected e.g. 5681 or \"5681\"", data)
+	}
+
+	*p = Port(s)
+
+	return nil
+}
+
+// UnmarshalText parses a port given as a number or as a string in YAML or TOML
+// config files.
+func (p *Port) UnmarshalText(text []byte) error {
+	*p = Port(text)
+
+	return nil
+}
+
+// IsDefaulted reports whether the field with the given JSON name was missing
+// from the config file and set to its default value on load.
+func (c *RunnerConfig) IsDefaulted(field string) bool {
+	return slices.Contains(c.defaulted, field)
+}
+
+// BrokerURIForRunners returns the URI of the task broker server for runners to
+// connect to.
+func (c *BaseConfig) BrokerURIForRunners() string {
+	if c.RunnerBrokerURI != "" {
+		return c.RunnerBrokerURI
+	}
+
+	return c.TaskBrokerURI
 }
 
 // LoadLauncherConfig loads the launcher's base config from the launcher's environment and
 // loads runner configs from the config file specified by N8N_RUNNERS_CONFIG_PATH.
-func LoadLauncherConfig(runnerTypes []string, baseLookuper envconfig.Lookuper) (*LauncherConfig, error) {
+func LoadLauncherConfig(runnerNames []string, baseLookuper envconfig.Lookuper) (*LauncherConfig, error) {
 	ctx := context.Background()
 
+	lookuper := NewLauncherLookuper(baseLookuper)
+
 	var baseConfig BaseConfig
 	if err := envconfig.ProcessWith(ctx, &envconfig.Config{
 		Target:   &baseConfig,
-		Lookuper: NewLauncherLookuper(baseLookuper),
+		Lookuper: lookuper,
 	}); err != nil {
 		return nil, err
 	}
 
 	var cfgErrs []error
 
-	if err := validateURL(baseConfig.TaskBrokerURI, "N8N_RUNNERS_TASK_BROKER_URI"); err != nil {
+	if err := validateBrokerURI(baseConfig.TaskBrokerURI, "N8N_RUNNERS_TASK_BROKER_URI"); err != nil {
 		cfgErrs = append(cfgErrs, err)
 	}
 
+	if baseConfig.RunnerBrokerURI != "" {
+		if err := validateURL(baseConfig.RunnerBrokerURI, "N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI"); err != nil {
+			cfgErrs = append(cfgErrs, err)
+		}
+	} else if strings.HasPrefix(baseConfig.TaskBrokerURI, "unix:") {
+		cfgErrs = append(cfgErrs, errors.New("N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI is required with a unix:// N8N_RUNNERS_TASK_BROKER_URI, as runners can only connect to the task broker over TCP"))
+	}
+
 	timeoutInt, err := strconv.Atoi(baseConfig.AutoShutdownTimeout)
 	if err != nil {
 		cfgErrs = append(cfgErrs, errs.ErrNonIntegerAutoShutdownTimeout)
@@ -122,6 +317,52 @@ func LoadLauncherConfig(runnerTypes []string, baseLookuper envconfig.Lookuper) (
 		cfgErrs = append(cfgErrs, fmt.Errorf("%s must be a valid port number", EnvVarHealthCheckPort))
 	}
 
+	if !logs.IsValidFormat(baseConfig.LogFormat) {
+		cfgErrs = append(cfgErrs, errors.New("N8N_RUNNERS_LAUNCHER_LOG_FORMAT must be one of: text, json"))
+	}
+
+	if baseConfig.ShutdownGracePeriod < 0 {
+		cfgErrs = append(cfgErrs, errs.ErrNegativeShutdownGracePeriod)
+	}
+
+	backoffConfigs := []struct {
+		envVarPrefix string
+		cfg          *BackoffConfig
+	}{
+		{"N8N_RUNNERS_LAUNCHER_READINESS_CHECK_BACKOFF", baseConfig.ReadinessCheckBackoff},
+		{"N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_BACKOFF", baseConfig.GrantTokenFetchBackoff},
+	}
+	for _, b := range backoffConfigs {
+		if _, err := retry.NewBackoff(b.cfg.Strategy, b.cfg.BaseDelay, b.cfg.MaxDelay); err != nil {
+			cfgErrs = append(cfgErrs, fmt.Errorf("%s is invalid: %w", b.envVarPrefix, err))
+		}
+	}
+
+	timeouts := []struct {
+		envVar  string
+		timeout time.Duration
+	}{
+		{"N8N_RUNNERS_LAUNCHER_READINESS_CHECK_TIMEOUT", baseConfig.ReadinessCheckTimeout},
+		{"N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_TIMEOUT", baseConfig.GrantTokenFetchTimeout},
+		{"N8N_RUNNERS_LAUNCHER_WS_PING_INTERVAL", baseConfig.WsPingInterval},
+		{"N8N_RUNNERS_LAUNCHER_WS_PONG_TIMEOUT", baseConfig.WsPongTimeout},
+	}
+	for _, t := range timeouts {
+		if t.timeout <= 0 {
+			cfgErrs = append(cfgErrs, fmt.Errorf("%s must be positive", t.envVar))
+		}
+	}
+
+	if baseConfig.OfferRenewalInterval < 0 || (baseConfig.OfferRenewalInterval > 0 && baseConfig.OfferRenewalInterval < time.Millisecond) {
+		cfgErrs = append(cfgErrs, errors.New("N8N_RUNNERS_LAUNCHER_OFFER_RENEWAL_INTERVAL must be 0 or at least 1ms"))
+	}
+
+	tlsSettings := baseConfig.BrokerTLS
+	brokerTLSConfig, err := tlsconfig.New(tlsSettings.CAFile, tlsSettings.CertFile, tlsSettings.KeyFile, tlsSettings.ServerName)
+	if err != nil {
+		cfgErrs = append(cfgErrs, fmt.Errorf("N8N_RUNNERS_TASK_BROKER_TLS_* is invalid: %w", err))
+	}
+
 	if baseConfig.Sentry.Dsn != "" {
 		if err := validateURL(baseConfig.Sentry.Dsn, "SENTRY_DSN"); err != nil {
 			cfgErrs = append(cfgErrs, err)
@@ -132,7 +373,7 @@ func LoadLauncherConfig(runnerTypes []string, baseLookuper envconfig.Lookuper) (
 
 	// runners
 
-	runnerConfigs, err := readLauncherConfigFile(baseConfig.ConfigPath, runnerTypes)
+	runnerConfigs, err := readLauncherConfigFile(baseConfig.ConfigPath, runnerNames, lookuper)
 	if err != nil {
 		cfgErrs = append(cfgErrs, err)
 	}
@@ -142,14 +383,20 @@ func LoadLauncherConfig(runnerTypes []string, baseLookuper envconfig.Lookuper) (
 	}
 
 	return &LauncherConfig{
-		BaseConfig:    &baseConfig,
-		RunnerConfigs: runnerConfigs,
+		BaseConfig:      &baseConfig,
+		BrokerTLSConfig: brokerTLSConfig,
+		RunnerConfigs:   runnerConfigs,
+		lookuper:        lookuper,
 	}, nil
 }
 
 // readLauncherConfigFile reads the config file at the specified path and
-// returns the runner config(s) for the requested runner type(s).
-func readLauncherConfigFile(configPath string, runnerTypes []string) (map[string]*RunnerConfig, error) {
+// returns the runner config(s) for the requested runner name(s), keyed by name,
+// with env vars in their values resolved by the lookuper. Every runner in the
+// file is checked for structural problems, so that a broken runner is reported
+// even if not requested, but env vars are resolved and workdirs checked only for
+// the requested runners. All problems are returned at once.
+func readLauncherConfigFile(configPath string, runnerNames []string, lookuper envconfig.Lookuper) (map[string]*RunnerConfig, error) {
 	// #nosec G304 -- configPath is controlled by system administrator via environment variable
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -157,9 +404,9 @@ func readLauncherConfigFile(configPath string, runnerTypes []string) (map[string
 	}
 
 	var fileConfig struct {
-		TaskRunners []RunnerConfig `json:"task-runners"`
+		TaskRunners []RunnerConfig `json:"task-runners" yaml:"task-runners" toml:"task-runners"`
 	}
-	if err := json.Unmarshal(data, &fileConfig); err != nil {
+	if err := decodeConfigFile(configPath, data, &fileConfig); err != nil {
 		return nil, fmt.Errorf("failed to parse config file at %s: %w", configPath, err)
 	}
 
@@ -169,37 +416,114 @@ func readLauncherConfigFile(configPath string, runnerTypes []string) (map[string
 		return nil, fmt.Errorf("config file at %s contains no task runners", configPath)
 	}
 
-	runnerConfigs := make(map[string]*RunnerConfig)
-	for _, runnerType := range runnerTypes {
-		found := false
-		for _, runnerConfig := range fileConfig.TaskRunners {
-			if runnerConfig.RunnerType == runnerType {
-				runnerConfigs[runnerType] = &runnerConfig
-				found = true
-				break
-			}
+	var cfgErrs []error
+
+	runnersByName := make(map[string]*RunnerConfig, taskRunnersNum)
+	var fileNames []string // in file order, for consistent errors
+	for i := range fileConfig.TaskRunners {
+		runnerConfig := &fileConfig.TaskRunners[i]
+		if runnerConfig.Name == "" {
+			runnerConfig.Name = runnerConfig.RunnerType
+			runnerConfig.defaulted = append(runnerConfig.defaulted, "name")
+		}
+		if err := validateRunnerName(runnerConfig); err != nil {
+			cfgErrs = append(cfgErrs, fmt.Errorf("config file at %s contains invalid runner: %w", configPath, err))
+			continue
+		}
+		if _, exists := runnersByName[runnerConfig.Name]; exists {
+			cfgErrs = append(cfgErrs, fmt.Errorf("config file at %s contains multiple runners named %q, set a unique `name` for each runner of the same type", configPath, runnerConfig.Name))
+			continue
 		}
+		runnersByName[runnerConfig.Name] = runnerConfig
+		fileNames = append(fileNames, runnerConfig.Name)
+	}
+
+	runnerConfigs := make(map[string]*RunnerConfig)
+	for _, runnerName := range runnerNames {
+		runnerConfig, found := runnersByName[runnerName]
 		if !found {
-			return nil, fmt.Errorf("config file at %s does not contain requested runner type: %s", configPath, runnerType)
+			cfgErrs = append(cfgErrs, fmt.Errorf("config file at %s does not contain requested runner: %s", configPath, runnerName))
+			continue
 		}
+		if _, requested := runnerConfigs[runnerName]; requested {
+			cfgErrs = append(cfgErrs, fmt.Errorf("runner %s is requested more than once", runnerName))
+			continue
+		}
+		runnerConfigs[runnerName] = runnerConfig
 	}
 
-	if len(runnerConfigs) == 1 {
-		for _, config := range runnerConfigs {
-			if config.HealthCheckServerPort == "" {
+	portsToValidate := make(map[string]*RunnerConfig, len(runnerConfigs))
+	for _, runnerName := range fileNames {
+		config := runnersByName[runnerName]
+		_, requested := runnerConfigs[runnerName]
+
+		// env vars may be set only for the runners the launcher was started with
+		if requested {
+			if interpolationErrs := interpolateRunnerConfig(config, lookuper); len(interpolationErrs) > 0 {
+				for _, err := range interpolationErrs {
+					cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
+				}
+				continue // other checks would fail on values not expanded
+			}
+		}
+
+		if constraintErrs := checkConstraints("", reflect.ValueOf(config).Elem()); len(constraintErrs) > 0 {
+			for _, err := range constraintErrs {
+				cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
+			}
+			continue // other checks assume values within constraints
+		}
+
+		if config.HealthCheckServerPort == "" && requested { // only required with other runners launched alongside
+			if len(runnerNames) == 1 {
 				config.HealthCheckServerPort = "5681"
+				config.defaulted = append(config.defaulted, "health-check-server-port")
+			} else {
+				cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: health-check-server-port is required with multiple runners", runnerName))
 			}
 		}
-	} else {
-		for runnerType, config := range runnerConfigs {
-			if config.HealthCheckServerPort == "" {
-				return nil, fmt.Errorf("runner %s: health-check-server-port is required with multiple runners", runnerType)
+
+		if config.MaxConcurrent == 0 {
+			config.MaxConcurrent = 1
+			config.defaulted = append(config.defaulted, "max-concurrent")
+		}
+
+		if err := validateRunnerPool(config); err != nil {
+			cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
+		} else if config.HealthCheckServerPort != "" && requested {
+			portsToValidate[runnerName] = config
+		} else if config.HealthCheckServerPort != "" {
+			// ports may only overlap with runners not launched alongside
+			if err := claimRunnerPorts(runnerName, config, map[string]string{}); err != nil {
+				cfgErrs = append(cfgErrs, err)
+			}
+		}
+
+		if requested { // may exist only where the runner is launched
+			if err := validateWorkDir(config.WorkDir); err != nil {
+				cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
 			}
 		}
+
+		if err := validateEnvPatterns("allowed-env", config.AllowedEnv); err != nil {
+			cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
+		}
+
+		if err := validateEnvPatterns("denied-env", config.DeniedEnv); err != nil {
+			cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
+		}
+
+		for _, err := range validateEnvFromFiles(config) {
+			cfgErrs = append(cfgErrs, fmt.Errorf("runner %s: %w", runnerName, err))
+		}
 	}
 
-	if err := validateRunnerPorts(runnerConfigs); err != nil {
-		return nil, err
+	if err := validateRunnerPorts(portsToValidate); err != nil {
+		cfgErrs = append(cfgErrs, err)
+	}
+
+	if len(cfgErrs) > 0 {
+		return nil, errors.Join(cfgErrs...)
 	}
 
 	if taskRunnersNum == 1 {
@@ -211,31 +535,143 @@ func readLauncherConfigFile(configPath string, runnerTypes []string) (map[string
 	return runnerConfigs, nil
 }
 
+// validateRunnerName checks that the runner has a type and that its name, or
+// its runner type if the name is defaulted, is safe to use verbatim, e.g. in the
+// runner's cgroup path.
+func validateRunnerName(config *RunnerConfig) error {
+	if err := fieldConstraints["runner-type"].check("runner-type", reflect.ValueOf(config.RunnerType)); err != nil {
+		return err
+	}
+
+	field := "name"
+	if config.IsDefaulted("name") {
+		field = "runner-type"
+	}
+
+	return fieldConstraints["name"].check(field, reflect.ValueOf(config.Name))
+}
+
+// reservedPorts are the ports used by n8n and the launcher, which runners'
+// health check servers must not use.
+var reservedPorts = map[string]string{
+	"5678": "n8n main server",
+	"5679": "n8n broker server",
+	"5680": "launcher health check server",
+}
+
 func validateRunnerPorts(runnerConfigs map[string]*RunnerConfig) error {
-	reservedPorts := map[string]string{
-		"5678": "n8n main server",
-		"5679": "n8n broker server",
-		"5680": "launcher health check server",
+	runnerNames := make([]string, 0, len(runnerConfigs))
+	for runnerName := range runnerConfigs {
+		runnerNames = append(runnerNames, runnerName)
 	}
+	sort.Strings(runnerNames) // ensure consistent order
 
 	usedPorts := make(map[string]string)
 
-	for runnerType, config := range runnerConfigs {
-		port := config.HealthCheckServerPort
-
-		if port, err := strconv.Atoi(port); err != nil || port <= 0 || port >= 65536 {
-			return fmt.Errorf("runner %s: health-check-server-port must be a valid port number", runnerType)
+	var cfgErrs []error
+	for _, runnerName := range runnerNames {
+		if err := claimRunnerPorts(runnerName, runnerConfigs[runnerName], usedPorts); err != nil {
+			cfgErrs = append(cfgErrs, err)
 		}
+	}
+
+	return errors.Join(cfgErrs...)
+}
+
+// claimRunnerPorts checks the ports used by the runner and records them as used.
+func claimRunnerPorts(runnerName string, config *RunnerConfig, usedPorts map[string]string) error {
+	basePort, err := strconv.Atoi(string(config.HealthCheckServerPort))
+	if err != nil || basePort <= 0 || basePort >= 65536 {
+		return fmt.Errorf("runner %s: health-check-server-port must be a valid port number", runnerName)
+	}
+
+	// every concurrent runner uses the next port after the previous runner's
+	instances := max(config.MaxConcurrent, 1)
+	if basePort+instances-1 >= 65536 {
+		return fmt.Errorf("runner %s: health-check-server-port %d is too high for max-concurrent %d", runnerName, basePort, instances)
+	}
+
+	for i := range instances {
+		port := strconv.Itoa(basePort + i)
 
 		if service, exists := reservedPorts[port]; exists {
-			return fmt.Errorf("runner %s: health-check-server-port %s conflicts with %s", runnerType, port, service)
+			return fmt.Errorf("runner %s: health-check-server-port %s conflicts with %s", runnerName, port, service)
 		}
 
 		if existingRunner, exists := usedPorts[port]; exists {
-			return fmt.Errorf("runners %s and %s cannot use the same health-check-server-port %s", existingRunner, runnerType, port)
+			return fmt.Errorf("runners %s and %s cannot use the same health-check-server-port %s", existingRunner, runnerName, port)
 		}
+	}
+
+	for i := range instances {
+		usedPorts[strconv.Itoa(basePort+i)] = runnerName
+	}
+
+	return nil
+}
+
+// validateRunnerPool checks that the runner's `min-idle` and `max-concurrent`
+// are consistent with each other.
+func validateRunnerPool(config *RunnerConfig) error {
+	if config.MinIdle > config.MaxConcurrent {
+		return fmt.Errorf("min-idle (%d) must not exceed max-concurrent (%d)", config.MinIdle, config.MaxConcurrent)
+	}
+
+	return nil
+}
+
+// validateEnvPatterns checks that every env var name or glob pattern in the
+// field is valid.
+func validateEnvPatterns(field string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q", field, pattern)
+		}
+	}
+
+	return nil
+}
+
+// validateEnvFromFiles checks that every `env-from-files` entry maps a valid env
+// var name, not also in `env-overrides`, to a file path. Files are read only on
+// launch, so they need not exist yet.
+func validateEnvFromFiles(config *RunnerConfig) []error {
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(config.EnvFromFiles)) {
+		switch {
+		case !envVarNameRegex.MatchString(key):
+			errs = append(errs, fmt.Errorf("env-from-files: invalid env var name %q", key))
+		case config.EnvFromFiles[key] == "":
+			errs = append(errs, fmt.Errorf("env-from-files.%s: file path is required", key))
+		}
+
+		if _, ok := config.EnvOverrides[key]; ok {
+			errs = append(errs, fmt.Errorf("env-from-files.%s: also set in env-overrides", key))
+		}
+	}
+
+	return errs
+}
+
+// validateWorkDir checks that the workdir exists, is a directory, and can be
+// used as the working directory of a runner process.
+func validateWorkDir(workDir string) error {
+	if workDir == "" {
+		return errors.New("workdir is required")
+	}
+
+	info, err := os.Stat(workDir)
+	if err != nil {
+		return fmt.Errorf("workdir %s is not accessible: %w", workDir, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("workdir %s is not a directory", workDir)
+	}
 
-		usedPorts[port] = runnerType
+	// a process can only use as working dir a dir it has search permission on
+	if err := unix.Access(workDir, unix.X_OK); err != nil {
+		return fmt.Errorf("workdir %s is not accessible: %w", workDir, err)
 	}
 
 	return nil
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"task-runner-launcher/internal/limits"
 	"testing"
 
 	"github.com/sethvargo/go-envconfig"
@@ -11,12 +14,13 @@ import (
 )
 
 func TestLoadConfig(t *testing.T) {
+	workDir := t.TempDir()
 	testConfigPath := filepath.Join(t.TempDir(), "testconfig.json")
 
 	validConfigContent := `{
 		"task-runners": [{
 			"runner-type": "javascript",
-			"workdir": "/test/dir",
+			"workdir": "` + workDir + `",
 			"command": "node",
 			"args": ["/test/start.js"],
 			"allowed-env": ["PATH", "NODE_ENV"]
@@ -55,6 +59,99 @@ func TestLoadConfig(t *testing.T) {
 			runnerType:    "javascript",
 			expectedError: false,
 		},
+		{
+			name:          "negative shutdown grace period",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":                     "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":                "http://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":                    testConfigPath,
+				"N8N_RUNNERS_LAUNCHER_SHUTDOWN_GRACE_PERIOD": "-1",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "negative shutdown grace period",
+		},
+		{
+			name:          "invalid log format",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":          "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":     "http://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":         testConfigPath,
+				"N8N_RUNNERS_LAUNCHER_LOG_FORMAT": "xml",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "N8N_RUNNERS_LAUNCHER_LOG_FORMAT must be one of",
+		},
+		{
+			name:          "invalid backoff strategy",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":                       "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":                  "http://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":                      testConfigPath,
+				"N8N_RUNNERS_LAUNCHER_READINESS_CHECK_BACKOFF": "linear",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "N8N_RUNNERS_LAUNCHER_READINESS_CHECK_BACKOFF is invalid",
+		},
+		{
+			name:          "max delay below base delay",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":                                   "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":                              "http://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":                                  testConfigPath,
+				"N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_BACKOFF":           "exponential",
+				"N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_BACKOFF_MAX_DELAY": "1s",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "N8N_RUNNERS_LAUNCHER_GRANT_TOKEN_FETCH_BACKOFF is invalid",
+		},
+		{
+			name:          "non-positive pong timeout",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":               "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":          "http://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":              testConfigPath,
+				"N8N_RUNNERS_LAUNCHER_WS_PONG_TIMEOUT": "0s",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "N8N_RUNNERS_LAUNCHER_WS_PONG_TIMEOUT must be positive",
+		},
+		{
+			name:          "negative offer renewal interval",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":                      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":                 "http://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":                     testConfigPath,
+				"N8N_RUNNERS_LAUNCHER_OFFER_RENEWAL_INTERVAL": "-1s",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "N8N_RUNNERS_LAUNCHER_OFFER_RENEWAL_INTERVAL must be 0 or at least 1ms",
+		},
+		{
+			name:          "client cert without key",
+			configContent: validConfigContent,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":                  "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":             "https://127.0.0.1:5679",
+				"N8N_RUNNERS_CONFIG_PATH":                 testConfigPath,
+				"N8N_RUNNERS_TASK_BROKER_TLS_CERT_FILE":   "/etc/launcher/client.pem",
+				"N8N_RUNNERS_TASK_BROKER_TLS_SERVER_NAME": "n8n.internal",
+			},
+			runnerType:    "javascript",
+			expectedError: true,
+			errorMsg:      "N8N_RUNNERS_TASK_BROKER_TLS_* is invalid: client cert file and key file must be set together",
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,6 +175,7 @@ func TestLoadConfig(t *testing.T) {
 }
 
 func TestConfigFileErrors(t *testing.T) {
+	workDir := t.TempDir()
 	testConfigPath := filepath.Join(t.TempDir(), "testconfig.json")
 
 	tests := []struct {
@@ -109,17 +207,186 @@ func TestConfigFileErrors(t *testing.T) {
 			},
 		},
 		{
-			name: "runner type not found",
+			name: "runner not found",
 			configContent: `{
 				"task-runners": [{
 					"runner-type": "python",
-					"workdir": "/test/dir",
+					"workdir": "` + workDir + `",
 					"command": "python",
 					"args": ["/test/start.py"],
 					"allowed-env": ["PATH", "PYTHONPATH"]
 				}]
 			}`,
-			expectedError: "does not contain requested runner type: javascript",
+			expectedError: "does not contain requested runner: javascript",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "invalid memory limit",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"],
+					"limits": {"memory-max": "512X"}
+				}]
+			}`,
+			expectedError: "invalid size",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "invalid env pattern",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"],
+					"allowed-env": ["OTEL_*"],
+					"denied-env": ["[OTEL"]
+				}]
+			}`,
+			expectedError: `runner javascript: denied-env: invalid pattern "[OTEL"`,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "invalid env-from-files",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"],
+					"env-overrides": {"DB_PASSWORD": "plain"},
+					"env-from-files": {"DB_PASSWORD": "/run/secrets/db", "API-KEY": "/run/secrets/api", "EMPTY_PATH": ""}
+				}]
+			}`,
+			expectedError: `runner javascript: env-from-files: invalid env var name "API-KEY"`,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "unix broker URI without runner broker URI",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"]
+				}]
+			}`,
+			expectedError: "N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI is required with a unix:// N8N_RUNNERS_TASK_BROKER_URI",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "unix:///run/n8n/broker.sock",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "invalid runner broker URI",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"]
+				}]
+			}`,
+			expectedError: "N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI must use http:// or https:// scheme",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":                 "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI":            "unix:///run/n8n/broker.sock",
+				"N8N_RUNNERS_LAUNCHER_RUNNER_BROKER_URI": "unix:///run/n8n/broker.sock",
+				"N8N_RUNNERS_CONFIG_PATH":                testConfigPath,
+			},
+		},
+		{
+			name: "invalid cpu limit",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"],
+					"limits": {"cpu-max": 0.001}
+				}]
+			}`,
+			expectedError: "runner javascript: limits.cpu-max must be at least 0.01",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "missing command",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"args": ["/test/start.js"]
+				}]
+			}`,
+			expectedError: "runner javascript: command is required",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "missing runner type",
+			configContent: `{
+				"task-runners": [{
+					"name": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node"
+				}]
+			}`,
+			expectedError: "contains invalid runner: runner-type is required",
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name: "unknown field",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["/test/start.js"],
+					"allowed_env": ["PATH"]
+				}]
+			}`,
+			expectedError: `unknown field "allowed_env"`,
+			envVars: map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			},
+		},
+		{
+			name:          "data after config",
+			configContent: `{"task-runners": []} {}`,
+			expectedError: "invalid data after top-level value",
 			envVars: map[string]string{
 				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
 				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
@@ -145,6 +412,455 @@ func TestConfigFileErrors(t *testing.T) {
 	}
 }
 
+func TestConfigFileFormats(t *testing.T) {
+	workDir := t.TempDir()
+
+	expected := RunnerConfig{
+		RunnerType:            "javascript",
+		Name:                  "js",
+		WorkDir:               workDir,
+		Command:               "node",
+		Args:                  []string{"start.js"},
+		HealthCheckServerPort: "5690",
+		MaxConcurrent:         2,
+		Limits:                &limits.Spec{MemoryMax: 512 << 20, PidsMax: 64},
+		AllowedEnv:            []string{"PATH", "NODE_OPTIONS"},
+		EnvOverrides:          map[string]string{"TZ": "UTC"},
+	}
+
+	tests := []struct {
+		name          string
+		fileName      string
+		configContent string
+		expectedError string
+	}{
+		{
+			name:     "json",
+			fileName: "config.json",
+			configContent: `{
+				"task-runners": [{
+					"runner-type": "javascript",
+					"name": "js",
+					"workdir": "` + workDir + `",
+					"command": "node",
+					"args": ["start.js"],
+					"health-check-server-port": 5690,
+					"max-concurrent": 2,
+					"limits": {"memory-max": "512M", "pids-max": 64},
+					"allowed-env": ["PATH", "NODE_OPTIONS"],
+					"env-overrides": {"TZ": "UTC"}
+				}]
+			}`,
+		},
+		{
+			name:     "yaml",
+			fileName: "config.yaml",
+			configContent: `task-runners:
+  - runner-type: javascript
+    name: js
+    workdir: ` + workDir + `
+    command: node
+    args: [start.js]
+    health-check-server-port: 5690
+    max-concurrent: 2
+    limits:
+      memory-max: 512M
+      pids-max: 64
+    allowed-env:
+      - PATH # needed to find node
+      - NODE_OPTIONS
+    env-overrides:
+      TZ: UTC
+`,
+		},
+		{
+			name:     "yml",
+			fileName: "config.yml",
+			configContent: `task-runners:
+  - {runner-type: javascript, name: js, workdir: ` + workDir + `, command: node, args: [start.js], health-check-server-port: "5690", max-concurrent: 2, limits: {memory-max: 536870912, pids-max: 64}, allowed-env: [PATH, NODE_OPTIONS], env-overrides: {TZ: UTC}}
+`,
+		},
+		{
+			name:     "toml",
+			fileName: "config.toml",
+			configContent: `[[task-runners]]
+runner-type = "javascript"
+name = "js"
+workdir = "` + workDir + `"
+command = "node"
+args = ["start.js"]
+health-check-server-port = 5690
+max-concurrent = 2
+allowed-env = [
+  "PATH", # needed to find node
+  "NODE_OPTIONS",
+]
+limits = { memory-max = "512M", pids-max = 64 }
+
+[task-runners.env-overrides]
+TZ = "UTC"
+`,
+		},
+		{
+			name:     "unknown field in json",
+			fileName: "config.json",
+			configContent: `{"task-runners": [{
+  "runner-type": "javascript",
+  "workdir": "` + workDir + `",
+  "command": "node", "allowed_env": ["PATH"]
+}]}`,
+			expectedError: `line 4, column 22: unknown field "allowed_env"`,
+		},
+		{
+			name:     "syntax error in json",
+			fileName: "config.json",
+			configContent: `{"task-runners": [{
+  "runner-type": "javascript",
+  "workdir": "` + workDir + `"
+  "command": "node"
+}]}`,
+			expectedError: `line 4, column 3: invalid character '"' after object key:value pair`,
+		},
+		{
+			name:     "invalid type in json",
+			fileName: "config.json",
+			configContent: `{"task-runners": [{
+  "runner-type": "javascript",
+  "workdir": "` + workDir + `",
+  "command": "node",
+  "max-concurrent": "2"
+}]}`,
+			expectedError: `line 5, column 23: json: cannot unmarshal string into Go struct field`,
+		},
+		{
+			name:     "invalid port in json",
+			fileName: "config.json",
+			configContent: `{"task-runners": [{
+  "runner-type": "javascript",
+  "workdir": "` + workDir + `",
+  "command": "node",
+  "health-check-server-port": true
+}]}`,
+			expectedError: `invalid port true, expected e.g. 5681 or "5681"`,
+		},
+		{
+			name:     "unknown field in yaml",
+			fileName: "config.yaml",
+			configContent: `task-runners:
+  - runner-type: javascript
+    workdir: ` + workDir + `
+    command: node
+    allowed_env: [PATH]
+`,
+			expectedError: `line 5, column 5: unknown field "allowed_env"`,
+		},
+		{
+			name:     "unknown nested field in yaml",
+			fileName: "config.yaml",
+			configContent: `task-runners:
+  - runner-type: javascript
+    workdir: ` + workDir + `
+    command: node
+    limits: {memory: 512M}
+`,
+			expectedError: `line 5, column 14: unknown field "memory"`,
+		},
+		{
+			name:     "unknown field in toml",
+			fileName: "config.toml",
+			configContent: `[[task-runners]]
+runner-type = "javascript"
+workdir = "` + workDir + `"
+command = "node"
+allowed_env = ["PATH"]
+`,
+			expectedError: `line 5, column 1: unknown field "allowed_env"`,
+		},
+		{
+			name:     "invalid value in toml",
+			fileName: "config.toml",
+			configContent: `[[task-runners]]
+runner-type = "javascript"
+workdir = "` + workDir + `"
+command = "node"
+limits = { memory-max = "512X" }
+`,
+			expectedError: `line 5, column 25: invalid size "512X"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), tt.fileName)
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.configContent), 0600))
+
+			configs, err := readLauncherConfigFile(configPath, []string{"js"}, envconfig.MapLookuper(nil))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			actual := *configs["js"]
+			actual.defaulted = nil
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestConfigFileReportsAllErrors(t *testing.T) {
+	workDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"task-runners": [
+			{
+				"runner-type": "javascript",
+				"workdir": "` + workDir + `",
+				"command": "node",
+				"health-check-server-port": "5679",
+				"limits": {"cpu-max": 0.001}
+			},
+			{
+				"runner-type": "python",
+				"workdir": "/nonexistent",
+				"command": "python",
+				"min-idle": 2
+			},
+			{
+				"runner-type": "python",
+				"workdir": "` + workDir + `",
+				"command": "python"
+			}
+		]
+	}`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+
+	_, err := readLauncherConfigFile(configPath, []string{"javascript", "python", "go"}, envconfig.MapLookuper(nil))
+	require.Error(t, err)
+
+	for _, expected := range []string{
+		`contains multiple runners named "python"`,
+		"does not contain requested runner: go",
+		"runner python: health-check-server-port is required with multiple runners",
+		"runner python: min-idle (2) must not exceed max-concurrent (1)",
+		"runner python: workdir /nonexistent is not accessible",
+		"runner javascript: limits.cpu-max must be at least 0.01",
+		"runner javascript: health-check-server-port 5679 conflicts with n8n broker server",
+	} {
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestUnknownFieldsAreAllReported(t *testing.T) {
+	workDir := t.TempDir()
+
+	tests := []struct {
+		name           string
+		fileName       string
+		configContent  string
+		expectedErrors []string
+	}{
+		{
+			name:     "json",
+			fileName: "config.json",
+			configContent: `{"task-runners": [{
+  "runner-type": "javascript",
+  "workdir": "` + workDir + `",
+  "command": "node",
+  "allowed_env": ["PATH"],
+  "limits": {"memory": "512M"}
+}]}`,
+			expectedErrors: []string{
+				`line 5, column 3: unknown field "allowed_env"`,
+				`line 6, column 14: unknown field "memory"`,
+			},
+		},
+		{
+			name:     "yaml",
+			fileName: "config.yaml",
+			configContent: `task-runners:
+  - runner-type: javascript
+    workdir: ` + workDir + `
+    command: node
+    allowed_env: [PATH]
+    limits: {memory: 512M}
+`,
+			expectedErrors: []string{
+				`line 5, column 5: unknown field "allowed_env"`,
+				`line 6, column 14: unknown field "memory"`,
+			},
+		},
+		{
+			name:     "toml",
+			fileName: "config.toml",
+			configContent: `[[task-runners]]
+runner-type = "javascript"
+workdir = "` + workDir + `"
+command = "node"
+allowed_env = ["PATH"]
+env_overrides = { TZ = "UTC" }
+`,
+			expectedErrors: []string{
+				`line 5, column 1: unknown field "allowed_env"`,
+				`line 6, column 1: unknown field "env_overrides"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), tt.fileName)
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.configContent), 0600))
+
+			_, err := readLauncherConfigFile(configPath, []string{"javascript"}, envconfig.MapLookuper(nil))
+			require.Error(t, err)
+			for _, expected := range tt.expectedErrors {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestNamedRunners(t *testing.T) {
+	workDir := t.TempDir()
+	testConfigPath := filepath.Join(t.TempDir(), "testconfig.json")
+
+	runnerEntry := func(name, port string) string {
+		nameField := ""
+		if name != "" {
+			nameField = `"name": "` + name + `",`
+		}
+		return `{
+			"runner-type": "javascript",
+			` + nameField + `
+			"workdir": "` + workDir + `",
+			"command": "node",
+			"args": ["/test/start.js"],
+			"health-check-server-port": "` + port + `"
+		}`
+	}
+
+	tests := []struct {
+		name          string
+		runners       []string
+		runnerNames   []string
+		expectedError string
+	}{
+		{
+			name:        "runner name defaults to runner type",
+			runners:     []string{runnerEntry("", "5681")},
+			runnerNames: []string{"javascript"},
+		},
+		{
+			name:        "multiple runners of same type selected by name",
+			runners:     []string{runnerEntry("js-node20", "5681"), runnerEntry("js-node22", "5682")},
+			runnerNames: []string{"js-node20", "js-node22"},
+		},
+		{
+			name:          "runner type does not select named runner",
+			runners:       []string{runnerEntry("js-node20", "5681")},
+			runnerNames:   []string{"javascript"},
+			expectedError: "does not contain requested runner: javascript",
+		},
+		{
+			name:          "duplicate runners of same type without names",
+			runners:       []string{runnerEntry("", "5681"), runnerEntry("", "5682")},
+			runnerNames:   []string{"javascript"},
+			expectedError: `contains multiple runners named "javascript"`,
+		},
+		{
+			name:          "duplicate runner names",
+			runners:       []string{runnerEntry("js", "5681"), runnerEntry("js", "5682")},
+			runnerNames:   []string{"js"},
+			expectedError: `contains multiple runners named "js"`,
+		},
+		{
+			name:          "name with path separator",
+			runners:       []string{runnerEntry("../js", "5681")},
+			runnerNames:   []string{"../js"},
+			expectedError: "contains invalid runner: name \"../js\" is invalid, expected only letters, digits",
+		},
+		{
+			name:          "dot-dot name",
+			runners:       []string{runnerEntry("..", "5681")},
+			runnerNames:   []string{".."},
+			expectedError: "contains invalid runner: name .. conflicts with the parent directory",
+		},
+		{
+			name:          "runner requested twice",
+			runners:       []string{runnerEntry("", "5681")},
+			runnerNames:   []string{"javascript", "javascript"},
+			expectedError: "runner javascript is requested more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{"task-runners": [` + strings.Join(tt.runners, ",") + `]}`
+			require.NoError(t, os.WriteFile(testConfigPath, []byte(content), 0600))
+
+			lookuper := envconfig.MapLookuper(map[string]string{
+				"N8N_RUNNERS_AUTH_TOKEN":      "test-token",
+				"N8N_RUNNERS_TASK_BROKER_URI": "http://localhost:5679",
+				"N8N_RUNNERS_CONFIG_PATH":     testConfigPath,
+			})
+			cfg, err := LoadLauncherConfig(tt.runnerNames, lookuper)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, cfg.RunnerConfigs, len(tt.runnerNames))
+			for _, runnerName := range tt.runnerNames {
+				runnerConfig := cfg.RunnerConfig(runnerName)
+				require.NotNil(t, runnerConfig)
+				assert.Equal(t, runnerName, runnerConfig.Name)
+				assert.Equal(t, "javascript", runnerConfig.RunnerType)
+			}
+		})
+	}
+}
+
+func TestReadLauncherConfigFileValidatesAllRunners(t *testing.T) {
+	workDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"task-runners": [
+			{"runner-type": "javascript", "workdir": "`+workDir+`", "command": "node", "health-check-server-port": "5681"},
+			{"runner-type": "python", "workdir": "`+workDir+`", "command": "python", "min-idle": 2},
+			{"runner-type": "ruby", "workdir": "`+workDir+`", "command": "ruby", "health-check-server-port": "99999"},
+			{"runner-type": "go", "workdir": "`+workDir+`", "command": "go", "health-check-server-port": "5681"}
+		]
+	}`), 0600))
+
+	_, err := readLauncherConfigFile(configPath, []string{"javascript"}, envconfig.MapLookuper(nil))
+
+	assert.ErrorContains(t, err, "runner python: min-idle (2) must not exceed max-concurrent (1)")
+	assert.ErrorContains(t, err, `runner ruby: health-check-server-port "99999" is invalid, expected a port number from 1 to 65535`)
+	assert.NotContains(t, err.Error(), "runner go", "runners not launched alongside may share ports")
+}
+
+func TestReadLauncherConfigFileResolvesOnlyRequestedRunners(t *testing.T) {
+	workDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+		"task-runners": [
+			{"runner-type": "javascript", "workdir": "`+workDir+`", "command": "${NODE_BIN}"},
+			{"runner-type": "python", "workdir": "/opt/nonexistent", "command": "${PY_BIN}"}
+		]
+	}`), 0600))
+
+	configs, err := readLauncherConfigFile(configPath, []string{"javascript"}, envconfig.MapLookuper(map[string]string{"NODE_BIN": "node"}))
+	require.NoError(t, err)
+	assert.Equal(t, "node", configs["javascript"].Command)
+
+	_, err = readLauncherConfigFile(configPath, []string{"python"}, envconfig.MapLookuper(map[string]string{"NODE_BIN": "node"}))
+	assert.ErrorContains(t, err, "runner python: command: variable PY_BIN is not set and has no default")
+}
+
 func TestValidateRunnerPorts(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -181,6 +897,29 @@ func TestValidateRunnerPorts(t *testing.T) {
 			},
 			expectedError: "must be a valid port number",
 		},
+		{
+			name: "overlapping port ranges with max-concurrent",
+			runnerConfigs: map[string]*RunnerConfig{
+				"javascript": {HealthCheckServerPort: "5681", MaxConcurrent: 3},
+				"python":     {HealthCheckServerPort: "5683"},
+			},
+			expectedError: "cannot use the same health-check-server-port 5683",
+		},
+		{
+			name: "adjacent port ranges with max-concurrent",
+			runnerConfigs: map[string]*RunnerConfig{
+				"javascript": {HealthCheckServerPort: "5681", MaxConcurrent: 3},
+				"python":     {HealthCheckServerPort: "5684", MaxConcurrent: 2},
+			},
+			expectedError: "",
+		},
+		{
+			name: "port range reaching reserved port",
+			runnerConfigs: map[string]*RunnerConfig{
+				"javascript": {HealthCheckServerPort: "5677", MaxConcurrent: 2},
+			},
+			expectedError: "5678 conflicts with n8n main server",
+		},
 		{
 			name: "port out of range",
 			runnerConfigs: map[string]*RunnerConfig{
@@ -204,25 +943,26 @@ func TestValidateRunnerPorts(t *testing.T) {
 }
 
 func TestBackwardsCompatibilityPortDefaults(t *testing.T) {
+	workDir := t.TempDir()
 	tests := []struct {
 		name          string
 		configContent string
 		runnerTypes   []string
 		expectError   bool
-		expectedPorts map[string]string
+		expectedPorts map[string]Port
 	}{
 		{
 			name: "single runner gets default port",
 			configContent: `{
 				"task-runners": [{
 					"runner-type": "javascript",
-					"workdir": "/test",
+					"workdir": "` + workDir + `",
 					"command": "node",
 					"args": ["test.js"]
 				}]
 			}`,
 			runnerTypes: []string{"javascript"},
-			expectedPorts: map[string]string{
+			expectedPorts: map[string]Port{
 				"javascript": "5681",
 			},
 		},
@@ -232,13 +972,13 @@ func TestBackwardsCompatibilityPortDefaults(t *testing.T) {
 				"task-runners": [
 					{
 						"runner-type": "javascript",
-						"workdir": "/test",
+						"workdir": "` + workDir + `",
 						"command": "node",
 						"args": ["test.js"]
 					},
 					{
 						"runner-type": "python", 
-						"workdir": "/test",
+						"workdir": "` + workDir + `",
 						"command": "python",
 						"args": ["test.py"]
 					}
@@ -255,7 +995,7 @@ func TestBackwardsCompatibilityPortDefaults(t *testing.T) {
 			err := os.WriteFile(testConfigPath, []byte(tt.configContent), 0600)
 			require.NoError(t, err)
 
-			configs, err := readLauncherConfigFile(testConfigPath, tt.runnerTypes)
+			configs, err := readLauncherConfigFile(testConfigPath, tt.runnerTypes, envconfig.MapLookuper(nil))
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -268,3 +1008,162 @@ func TestBackwardsCompatibilityPortDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateWorkDir(t *testing.T) {
+	workDir := t.TempDir()
+
+	filePath := filepath.Join(workDir, "file.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0600))
+
+	tests := []struct {
+		name          string
+		workDir       string
+		expectedError string
+	}{
+		{
+			name:    "existing dir",
+			workDir: workDir,
+		},
+		{
+			name:          "empty workdir",
+			workDir:       "",
+			expectedError: "workdir is required",
+		},
+		{
+			name:          "missing dir",
+			workDir:       filepath.Join(workDir, "missing"),
+			expectedError: "is not accessible",
+		},
+		{
+			name:          "file instead of dir",
+			workDir:       filePath,
+			expectedError: "is not a directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkDir(tt.workDir)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateRunnerPool(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        RunnerConfig
+		expectedError string
+	}{
+		{
+			name:   "single runner",
+			config: RunnerConfig{MaxConcurrent: 1},
+		},
+		{
+			name:   "warm runners within max",
+			config: RunnerConfig{MinIdle: 2, MaxConcurrent: 4},
+		},
+		{
+			name:          "min-idle above max-concurrent",
+			config:        RunnerConfig{MinIdle: 3, MaxConcurrent: 2},
+			expectedError: "must not exceed max-concurrent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRunnerPool(&tt.config)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestCheckConstraints(t *testing.T) {
+	valid := func() RunnerConfig {
+		return RunnerConfig{RunnerType: "javascript", WorkDir: "/tmp", Command: "node"}
+	}
+
+	tests := []struct {
+		name           string
+		modify         func(*RunnerConfig)
+		expectedErrors []string
+	}{
+		{
+			name:   "valid config",
+			modify: func(*RunnerConfig) {},
+		},
+		{
+			name: "unset max-concurrent",
+			modify: func(c *RunnerConfig) {
+				c.MaxConcurrent = 0
+				c.Limits = &limits.Spec{}
+			},
+		},
+		{
+			name:           "missing required fields",
+			modify:         func(c *RunnerConfig) { c.WorkDir, c.Command = "", "" },
+			expectedErrors: []string{"workdir is required", "command is required"},
+		},
+		{
+			name:           "max-concurrent below 1",
+			modify:         func(c *RunnerConfig) { c.MaxConcurrent = -1 },
+			expectedErrors: []string{"max-concurrent must be at least 1, got -1"},
+		},
+		{
+			name:           "negative min-idle",
+			modify:         func(c *RunnerConfig) { c.MinIdle = -1 },
+			expectedErrors: []string{"min-idle must be at least 0, got -1"},
+		},
+		{
+			name:           "invalid port",
+			modify:         func(c *RunnerConfig) { c.HealthCheckServerPort = "0" },
+			expectedErrors: []string{`health-check-server-port "0" is invalid, expected a port number from 1 to 65535`},
+		},
+		{
+			name:           "reserved port",
+			modify:         func(c *RunnerConfig) { c.HealthCheckServerPort = "5680" },
+			expectedErrors: []string{"health-check-server-port 5680 conflicts with launcher health check server"},
+		},
+		{
+			name: "limits out of range",
+			modify: func(c *RunnerConfig) {
+				c.Limits = &limits.Spec{CPUMax: 0.001, PidsMax: -1, NofileMax: -1}
+			},
+			expectedErrors: []string{
+				"limits.cpu-max must be at least 0.01, got 0.001",
+				"limits.pids-max must be at least 0, got -1",
+				"limits.nofile-max must be at least 0, got -1",
+			},
+		},
+		{
+			name:           "negative cpu-max",
+			modify:         func(c *RunnerConfig) { c.Limits = &limits.Spec{CPUMax: -1} },
+			expectedErrors: []string{"limits.cpu-max must be at least 0.01, got -1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(&config)
+
+			errs := checkConstraints("", reflect.ValueOf(config))
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, tt.expectedErrors, messages)
+		})
+	}
+}
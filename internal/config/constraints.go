@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"task-runner-launcher/internal/limits"
+)
+
+// constraint restricts the values of a runner config field. Constraints are
+// enforced on config load and generate the runners config schema. An unset
+// field, i.e. one with a zero value, only needs to meet `required`.
+type constraint struct {
+	// required is whether the field must be set.
+	required bool
+
+	// pattern must match a string field, described by `expected` in errors.
+	pattern  *regexp.Regexp
+	expected string
+
+	// reserved are the values a string field must not have, with what each is
+	// reserved for.
+	reserved map[string]string
+
+	// minimum is the smallest value of a number field, if any.
+	minimum *float64
+}
+
+// runnerNameRegex matches the runner names that are safe to use verbatim in
+// cgroup paths, metric labels and logs, except for `.` and `..`.
+var runnerNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// portRegex matches port numbers from 1 to 65535.
+var portRegex = regexp.MustCompile(`^([1-9][0-9]{0,3}|[1-5][0-9]{4}|6[0-4][0-9]{3}|65[0-4][0-9]{2}|655[0-2][0-9]|6553[0-5])$`)
+
+func atLeast(minimum float64) *float64 {
+	return &minimum
+}
+
+// fieldConstraints are the constraints on runner config fields, including the
+// fields of `limits`, by field name in the config file.
+var fieldConstraints = map[string]constraint{
+	"name": {
+		pattern:  runnerNameRegex,
+		expected: "only letters, digits, `_`, `.` and `-`",
+		reserved: map[string]string{".": "the current directory", "..": "the parent directory"},
+	},
+	"runner-type": {required: true},
+	"workdir":     {required: true},
+	"command":     {required: true},
+	"health-check-server-port": {
+		pattern:  portRegex,
+		expected: "a port number from 1 to 65535",
+		reserved: reservedPorts,
+	},
+	"min-idle":       {minimum: atLeast(0)},
+	"max-concurrent": {minimum: atLeast(1)},
+	"cpu-max":        {minimum: atLeast(limits.MinCPUMax)},
+	"pids-max":       {minimum: atLeast(0)},
+	"nofile-max":     {minimum: atLeast(0)},
+}
+
+// check returns an error if the value of the field breaks the constraint.
+func (c constraint) check(field string, value reflect.Value) error {
+	if value.IsZero() {
+		if c.required {
+			return fmt.Errorf("%s is required", field)
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		s := value.String()
+		if c.pattern != nil && !c.pattern.MatchString(s) {
+			return fmt.Errorf("%s %q is invalid, expected %s", field, s, c.expected)
+		}
+		if reservedFor, ok := c.reserved[s]; ok {
+			return fmt.Errorf("%s %s conflicts with %s", field, s, reservedFor)
+		}
+	case reflect.Int:
+		if c.minimum != nil && float64(value.Int()) < *c.minimum {
+			return fmt.Errorf("%s must be at least %v, got %d", field, *c.minimum, value.Int())
+		}
+	case reflect.Float64:
+		if c.minimum != nil && value.Float() < *c.minimum {
+			return fmt.Errorf("%s must be at least %v, got %v", field, *c.minimum, value.Float())
+		}
+	}
+
+	return nil
+}
+
+// checkConstraints checks every field of the config struct, and of the structs
+// it points to, against its constraint, and returns an error per broken one.
+func checkConstraints(prefix string, value reflect.Value) []error {
+	var errs []error
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.Type().Elem().Kind() == reflect.Struct {
+			if !fieldValue.IsNil() {
+				errs = append(errs, checkConstraints(prefix+name+".", fieldValue.Elem())...)
+			}
+			continue
+		}
+
+		if err := fieldConstraints[name].check(prefix+name, fieldValue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
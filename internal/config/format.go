@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeConfigFile decodes the config file into `v` in the format given by the
+// file extension: YAML for `.yaml` and `.yml`, TOML for `.toml`, and JSON
+// otherwise. The config file must not contain unknown fields.
+func decodeConfigFile(configPath string, data []byte, v any) error {
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		return decodeYAML(data, v)
+	case ".toml":
+		return decodeTOML(data, v)
+	default:
+		return decodeJSON(data, v)
+	}
+}
+
+func decodeJSON(data []byte, v any) error {
+	var unknownFieldErrs []error
+	if err := checkJSONFields(json.NewDecoder(bytes.NewReader(data)), data, reflect.TypeOf(v), &unknownFieldErrs); err != nil {
+		return err
+	}
+	if len(unknownFieldErrs) > 0 {
+		return errors.Join(unknownFieldErrs...)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return withJSONPosition(data, err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid data after top-level value")
+	}
+
+	return nil
+}
+
+// checkJSONFields reads the next value from the decoder and adds an error
+// pointing at every key in it that does not match a field of the type it is
+// decoded into, or of any type if `t` is nil. It returns an error if the value
+// is not valid JSON.
+func checkJSONFields(decoder *json.Decoder, data []byte, t reflect.Type, unknownFieldErrs *[]error) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return withJSONPosition(data, err)
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil // scalar
+	}
+
+	for decoder.More() {
+		var valueType reflect.Type // nil on type mismatch, reported on decoding
+		switch {
+		case t == nil:
+		case delim == '[' && t.Kind() == reflect.Slice, delim == '{' && t.Kind() == reflect.Map:
+			valueType = t.Elem()
+		}
+
+		if delim == '{' {
+			start := jsonKeyStart(data, decoder.InputOffset())
+			key, err := decoder.Token()
+			if err != nil {
+				return withJSONPosition(data, err)
+			}
+
+			if t != nil && t.Kind() == reflect.Struct {
+				field, ok := taggedField(t, "json", key.(string))
+				if ok {
+					valueType = field.Type
+				} else {
+					line, column := jsonPosition(data, start)
+					*unknownFieldErrs = append(*unknownFieldErrs, fmt.Errorf("line %d, column %d: unknown field %q", line, column, key))
+				}
+			}
+		}
+
+		if err := checkJSONFields(decoder, data, valueType, unknownFieldErrs); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil { // closing delimiter
+		return withJSONPosition(data, err)
+	}
+
+	return nil
+}
+
+// jsonKeyStart returns the offset of the object key that follows the offset in
+// the data, past whitespace and the comma after the previous value.
+func jsonKeyStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) != -1 {
+		offset++
+	}
+
+	return offset
+}
+
+// withJSONPosition prefixes the JSON decoding error with the line and column of
+// the last byte read before the error, if known.
+func withJSONPosition(data []byte, err error) error {
+	var offset int64
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	line, column := jsonPosition(data, max(offset-1, 0))
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
+
+// jsonPosition returns the 1-based line and column of the offset in the data.
+func jsonPosition(data []byte, offset int64) (line, column int) {
+	before := data[:min(offset, int64(len(data)))]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+
+	return bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+func decodeYAML(data []byte, v any) error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	if len(document.Content) == 0 {
+		return nil // empty file
+	}
+
+	if err := checkYAMLFields(document.Content[0], reflect.TypeOf(v)); err != nil {
+		return err
+	}
+
+	return document.Content[0].Decode(v)
+}
+
+// checkYAMLFields returns an error pointing at every key in the node that does
+// not match a field of the type it is decoded into.
+func checkYAMLFields(node *yaml.Node, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if node.Kind == yaml.AliasNode {
+		return checkYAMLFields(node.Alias, t)
+	}
+
+	var errs []error
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := taggedField(t, "yaml", key.Value)
+			if !ok {
+				errs = append(errs, fmt.Errorf("line %d, column %d: unknown field %q", key.Line, key.Column, key.Value))
+				continue
+			}
+			errs = append(errs, checkYAMLFields(value, field.Type))
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			errs = append(errs, checkYAMLFields(item, t.Elem()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// taggedField returns the field of the struct type with the given name in its
+// `json` or `yaml` tag.
+func taggedField(t reflect.Type, tag, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tagName, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if tagName != "" && tagName == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func decodeTOML(data []byte, v any) error {
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+
+	var strictErr *toml.StrictMissingError
+	if errors.As(err, &strictErr) {
+		errs := make([]error, 0, len(strictErr.Errors))
+		for _, unknown := range strictErr.Errors {
+			line, column := unknown.Position()
+			key := unknown.Key()
+			errs = append(errs, fmt.Errorf("line %d, column %d: unknown field %q", line, column, key[len(key)-1]))
+		}
+		return errors.Join(errs...)
+	}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		line, column := decodeErr.Position()
+		return fmt.Errorf("line %d, column %d: %s", line, column, strings.TrimPrefix(decodeErr.Error(), "toml: "))
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// interpolate expands `${VAR}` and `${VAR:-default}` in the value with the env
+// vars found by the lookuper. The default applies if the env var is unset or
+// empty. `$${` is an escaped, literal `${`.
+func interpolate(value string, lookuper envconfig.Lookuper) (string, error) {
+	var result strings.Builder
+
+	for {
+		start := strings.Index(value, "${")
+		if start == -1 {
+			result.WriteString(value)
+			return result.String(), nil
+		}
+
+		if start > 0 && value[start-1] == '$' {
+			result.WriteString(value[:start-1] + "${")
+			value = value[start+2:]
+			continue
+		}
+
+		length := strings.IndexByte(value[start:], '}')
+		if length == -1 {
+			return "", errors.New("missing closing brace after ${")
+		}
+
+		name, defaultValue, hasDefault := strings.Cut(value[start+2:start+length], ":-")
+		if !envVarNameRegex.MatchString(name) {
+			return "", fmt.Errorf("invalid variable name %q", name)
+		}
+
+		resolved, found := lookuper.Lookup(name)
+		if !found || (hasDefault && resolved == "") {
+			if !hasDefault {
+				return "", fmt.Errorf("variable %s is not set and has no default", name)
+			}
+			resolved = defaultValue
+		}
+
+		result.WriteString(value[:start] + resolved)
+		value = value[start+length+1:]
+	}
+}
+
+// interpolateRunnerConfig expands env vars in the runner's command, workdir,
+// args, env-override values and env-from-files paths, and returns an error per
+// value that fails to expand.
+func interpolateRunnerConfig(config *RunnerConfig, lookuper envconfig.Lookuper) []error {
+	var errs []error
+	expand := func(field string, value *string) {
+		expanded, err := interpolate(*value, lookuper)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+			return
+		}
+		*value = expanded
+	}
+
+	expand("command", &config.Command)
+	expand("workdir", &config.WorkDir)
+	for i := range config.Args {
+		expand(fmt.Sprintf("args[%d]", i), &config.Args[i])
+	}
+
+	expandValues := func(field string, values map[string]string) {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // ensure consistent order
+
+		for _, key := range keys {
+			value := values[key]
+			expand(field+"."+key, &value)
+			values[key] = value
+		}
+	}
+
+	expandValues("env-overrides", config.EnvOverrides)
+	expandValues("env-from-files", config.EnvFromFiles)
+
+	return errs
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sethvargo/go-envconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	lookuper := envconfig.MapLookuper(map[string]string{
+		"NODE_DIR": "/opt/node",
+		"EMPTY":    "",
+	})
+
+	tests := []struct {
+		name          string
+		value         string
+		expected      string
+		expectedError string
+	}{
+		{name: "no variables", value: "/usr/bin/node", expected: "/usr/bin/node"},
+		{name: "variable", value: "${NODE_DIR}/bin/node", expected: "/opt/node/bin/node"},
+		{name: "multiple variables", value: "${NODE_DIR}:${NODE_DIR}", expected: "/opt/node:/opt/node"},
+		{name: "set variable ignores default", value: "${NODE_DIR:-/usr}", expected: "/opt/node"},
+		{name: "unset variable with default", value: "${MISSING:-/usr}/bin", expected: "/usr/bin"},
+		{name: "empty variable with default", value: "${EMPTY:-fallback}", expected: "fallback"},
+		{name: "empty variable without default", value: "a${EMPTY}b", expected: "ab"},
+		{name: "empty default", value: "${MISSING:-}", expected: ""},
+		{name: "escaped", value: "echo $${NODE_DIR}", expected: "echo ${NODE_DIR}"},
+		{name: "shell variable without braces", value: "echo $NODE_DIR $$", expected: "echo $NODE_DIR $$"},
+		{name: "unset variable without default", value: "${MISSING}", expectedError: "variable MISSING is not set and has no default"},
+		{name: "missing closing brace", value: "${NODE_DIR", expectedError: "missing closing brace"},
+		{name: "invalid name", value: "${NODE-DIR}", expectedError: `invalid variable name "NODE-DIR"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := interpolate(tt.value, lookuper)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRunnerConfigInterpolation(t *testing.T) {
+	workDir := t.TempDir()
+	secretPath := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte("s3cret\n"), 0600))
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+	}
+
+	lookuper := NewLauncherLookuper(envconfig.MapLookuper(map[string]string{
+		"WORK_DIR":         workDir,
+		"NODE_BIN":         "/opt/node/bin/node",
+		"API_SECRET_FILE":  secretPath,
+		"ENVIRONMENT_NAME": "staging",
+	}))
+
+	writeConfig(`{
+		"task-runners": [{
+			"runner-type": "javascript",
+			"workdir": "${WORK_DIR}",
+			"command": "${NODE_BIN:-node}",
+			"args": ["--env=${ENVIRONMENT_NAME}", "${ENTRYPOINT:-start.js}"],
+			"env-overrides": {"API_SECRET": "${API_SECRET}", "LITERAL": "$${NOT_EXPANDED}"},
+			"env-from-files": {"DB_PASSWORD": "${WORK_DIR}/db-password"}
+		}]
+	}`)
+
+	configs, err := readLauncherConfigFile(configPath, []string{"javascript"}, lookuper)
+	require.NoError(t, err)
+
+	runnerConfig := configs["javascript"]
+	assert.Equal(t, workDir, runnerConfig.WorkDir)
+	assert.Equal(t, "/opt/node/bin/node", runnerConfig.Command)
+	assert.Equal(t, []string{"--env=staging", "start.js"}, runnerConfig.Args)
+	assert.Equal(t, map[string]string{"API_SECRET": "s3cret", "LITERAL": "${NOT_EXPANDED}"}, runnerConfig.EnvOverrides)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": workDir + "/db-password"}, runnerConfig.EnvFromFiles)
+
+	writeConfig(`{
+		"task-runners": [{
+			"runner-type": "javascript",
+			"workdir": "${MISSING_DIR}",
+			"command": "node",
+			"args": ["${MISSING_ARG}"]
+		}]
+	}`)
+
+	_, err = readLauncherConfigFile(configPath, []string{"javascript"}, lookuper)
+	assert.ErrorContains(t, err, "runner javascript: workdir: variable MISSING_DIR is not set and has no default")
+	assert.ErrorContains(t, err, "runner javascript: args[0]: variable MISSING_ARG is not set and has no default")
+	assert.NotContains(t, err.Error(), "not accessible")
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"task-runner-launcher/internal/logs"
+	"time"
+)
+
+// ReloadRunnerConfigs re-reads the config file and re-validates it, replacing
+// the runner configs for the runners with the given names. Changes take effect
+// on the next runner launch. Changes to the runner type and to fields that size
+// the runner pool, i.e. `runner-type`, `health-check-server-port`, `min-idle`
+// and `max-concurrent`, require a restart, so a file changing any of them is
+// rejected, as other fields were validated against the changed values. If the
+// file is rejected or invalid, the current runner configs are kept and an
+// error is returned.
+func (c *LauncherConfig) ReloadRunnerConfigs(runnerNames []string) error {
+	newConfigs, err := readLauncherConfigFile(c.BaseConfig.ConfigPath, runnerNames, c.lookuper)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var restartErrs []error
+	for _, runnerName := range runnerNames {
+		oldConfig, newConfig := c.RunnerConfigs[runnerName], newConfigs[runnerName]
+
+		if oldConfig.RunnerType != newConfig.RunnerType ||
+			oldConfig.HealthCheckServerPort != newConfig.HealthCheckServerPort ||
+			oldConfig.MinIdle != newConfig.MinIdle ||
+			oldConfig.MaxConcurrent != newConfig.MaxConcurrent {
+			restartErrs = append(restartErrs, fmt.Errorf("runner %s: changes to runner-type, health-check-server-port, min-idle and max-concurrent require a restart", runnerName))
+		}
+	}
+	if len(restartErrs) > 0 {
+		return errors.Join(restartErrs...)
+	}
+
+	for _, runnerName := range runnerNames {
+		oldConfig, newConfig := c.RunnerConfigs[runnerName], newConfigs[runnerName]
+
+		changes := diffRunnerConfigs(oldConfig, newConfig)
+		if len(changes) == 0 {
+			newConfigs[runnerName] = oldConfig // keep identity so unchanged configs are not reapplied
+			continue
+		}
+
+		for _, change := range changes {
+			logs.Infof("Runner %s: %s", runnerName, change)
+		}
+	}
+
+	c.RunnerConfigs = newConfigs
+
+	return nil
+}
+
+// diffRunnerConfigs describes the changes between two runner configs. Values of
+// env overrides are not included, as they may contain secrets.
+func diffRunnerConfigs(oldConfig, newConfig *RunnerConfig) []string {
+	var changes []string
+
+	if oldConfig.WorkDir != newConfig.WorkDir {
+		changes = append(changes, fmt.Sprintf("workdir changed from %q to %q", oldConfig.WorkDir, newConfig.WorkDir))
+	}
+
+	if oldConfig.Command != newConfig.Command {
+		changes = append(changes, fmt.Sprintf("command changed from %q to %q", oldConfig.Command, newConfig.Command))
+	}
+
+	if !slices.Equal(oldConfig.Args, newConfig.Args) {
+		changes = append(changes, fmt.Sprintf("args changed from %q to %q", oldConfig.Args, newConfig.Args))
+	}
+
+	if !reflect.DeepEqual(oldConfig.Limits, newConfig.Limits) {
+		changes = append(changes, "limits changed")
+	}
+
+	added, removed := diffKeys(oldConfig.AllowedEnv, newConfig.AllowedEnv)
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("allowed-env added %v", added))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("allowed-env removed %v", removed))
+	}
+
+	added, removed = diffKeys(oldConfig.DeniedEnv, newConfig.DeniedEnv)
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("denied-env added %v", added))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("denied-env removed %v", removed))
+	}
+
+	added, removed = diffKeys(slices.Collect(maps.Keys(oldConfig.EnvOverrides)), slices.Collect(maps.Keys(newConfig.EnvOverrides)))
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("env-overrides added %v", added))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("env-overrides removed %v", removed))
+	}
+	var changed []string
+	for key, newValue := range newConfig.EnvOverrides {
+		if oldValue, ok := oldConfig.EnvOverrides[key]; ok && oldValue != newValue {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		changes = append(changes, fmt.Sprintf("env-overrides changed %v", changed))
+	}
+
+	added, removed = diffKeys(slices.Collect(maps.Keys(oldConfig.EnvFromFiles)), slices.Collect(maps.Keys(newConfig.EnvFromFiles)))
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("env-from-files added %v", added))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("env-from-files removed %v", removed))
+	}
+	changed = nil
+	for key, newPath := range newConfig.EnvFromFiles {
+		if oldPath, ok := oldConfig.EnvFromFiles[key]; ok && oldPath != newPath {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		changes = append(changes, fmt.Sprintf("env-from-files changed %v", changed))
+	}
+
+	return changes
+}
+
+// diffKeys returns the sorted keys present only in `newKeys` and only in `oldKeys`.
+func diffKeys(oldKeys, newKeys []string) (added, removed []string) {
+	for _, key := range newKeys {
+		if !slices.Contains(oldKeys, key) {
+			added = append(added, key)
+		}
+	}
+	for _, key := range oldKeys {
+		if !slices.Contains(newKeys, key) {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
+
+// WatchRunnerConfigs reloads the runner configs whenever the content of the
+// config file changes, checking at the given interval, and whenever a value is
+// received on `reload`, e.g. on SIGHUP. Runs until the context is cancelled.
+func (c *LauncherConfig) WatchRunnerConfigs(ctx context.Context, runnerNames []string, interval time.Duration, reload <-chan os.Signal) {
+	lastHash, _ := hashFile(c.BaseConfig.ConfigPath)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-reload:
+			logs.Infof("Received %s, reloading config file...", sig)
+		case <-ticker.C:
+			hash, err := hashFile(c.BaseConfig.ConfigPath)
+			if err != nil || hash == lastHash {
+				continue // on read error, keep current configs until file is readable again
+			}
+			logs.Info("Detected change in config file, reloading...")
+		}
+
+		lastHash, _ = hashFile(c.BaseConfig.ConfigPath)
+
+		if err := c.ReloadRunnerConfigs(runnerNames); err != nil {
+			logs.Errorf("Failed to reload config file, keeping current config: %v", err)
+			continue
+		}
+
+		logs.Info("Reloaded config file, changes will apply on next runner launch")
+	}
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	// #nosec G304 -- path is controlled by system administrator via environment variable
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(data), nil
+}
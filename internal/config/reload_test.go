@@ -0,0 +1,176 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-envconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRunnersFile(t *testing.T, path, workDir string, args string, port string) {
+	t.Helper()
+
+	content := `{
+		"task-runners": [{
+			"runner-type": "javascript",
+			"workdir": "` + workDir + `",
+			"command": "node",
+			"args": [` + args + `],
+			"health-check-server-port": "` + port + `",
+			"allowed-env": ["PATH"],
+			"env-overrides": {"SECRET": "value"}
+		}]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func loadTestLauncherConfig(t *testing.T, configPath string) *LauncherConfig {
+	t.Helper()
+
+	cfg, err := LoadLauncherConfig([]string{"javascript"}, envconfigLookuper(configPath))
+	require.NoError(t, err)
+
+	return cfg
+}
+
+func TestReloadRunnerConfigs(t *testing.T) {
+	workDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	t.Run("applies changed args", func(t *testing.T) {
+		writeRunnersFile(t, configPath, workDir, `"a.js"`, "5681")
+		cfg := loadTestLauncherConfig(t, configPath)
+		before := cfg.RunnerConfig("javascript")
+
+		writeRunnersFile(t, configPath, workDir, `"b.js"`, "5681")
+		require.NoError(t, cfg.ReloadRunnerConfigs([]string{"javascript"}))
+
+		after := cfg.RunnerConfig("javascript")
+		assert.Equal(t, []string{"b.js"}, after.Args)
+		assert.Equal(t, []string{"a.js"}, before.Args, "previous config should be left untouched for in-flight runners")
+	})
+
+	t.Run("keeps identity of unchanged config", func(t *testing.T) {
+		writeRunnersFile(t, configPath, workDir, `"a.js"`, "5681")
+		cfg := loadTestLauncherConfig(t, configPath)
+		before := cfg.RunnerConfig("javascript")
+
+		require.NoError(t, cfg.ReloadRunnerConfigs([]string{"javascript"}))
+
+		assert.Same(t, before, cfg.RunnerConfig("javascript"))
+	})
+
+	t.Run("rejects port change", func(t *testing.T) {
+		writeRunnersFile(t, configPath, workDir, `"a.js"`, "5681")
+		cfg := loadTestLauncherConfig(t, configPath)
+
+		writeRunnersFile(t, configPath, workDir, `"b.js"`, "5690")
+		err := cfg.ReloadRunnerConfigs([]string{"javascript"})
+
+		assert.ErrorContains(t, err, "runner javascript: changes to runner-type, health-check-server-port, min-idle and max-concurrent require a restart")
+		assert.Equal(t, Port("5681"), cfg.RunnerConfig("javascript").HealthCheckServerPort)
+		assert.Equal(t, []string{"a.js"}, cfg.RunnerConfig("javascript").Args)
+	})
+
+	t.Run("keeps current config on invalid file", func(t *testing.T) {
+		writeRunnersFile(t, configPath, workDir, `"a.js"`, "5681")
+		cfg := loadTestLauncherConfig(t, configPath)
+
+		require.NoError(t, os.WriteFile(configPath, []byte("invalid json"), 0600))
+		err := cfg.ReloadRunnerConfigs([]string{"javascript"})
+
+		assert.Error(t, err)
+		assert.Equal(t, []string{"a.js"}, cfg.RunnerConfig("javascript").Args)
+	})
+}
+
+func TestDiffRunnerConfigs(t *testing.T) {
+	oldConfig := &RunnerConfig{
+		Command:      "node",
+		Args:         []string{"a.js"},
+		AllowedEnv:   []string{"PATH", "HOME"},
+		DeniedEnv:    []string{"*_SECRET"},
+		EnvOverrides: map[string]string{"KEEP": "1", "CHANGE": "old-secret", "DROP": "x"},
+		EnvFromFiles: map[string]string{"DB_PASSWORD": "/run/secrets/db-old"},
+	}
+	newConfig := &RunnerConfig{
+		Command:      "node",
+		Args:         []string{"b.js"},
+		AllowedEnv:   []string{"PATH", "TZ"},
+		DeniedEnv:    []string{"*_TOKEN"},
+		EnvOverrides: map[string]string{"KEEP": "1", "CHANGE": "new-secret", "ADD": "y"},
+		EnvFromFiles: map[string]string{"DB_PASSWORD": "/run/secrets/db-new", "API_KEY": "/run/secrets/api"},
+	}
+
+	changes := diffRunnerConfigs(oldConfig, newConfig)
+
+	assert.Equal(t, []string{
+		`args changed from ["a.js"] to ["b.js"]`,
+		"allowed-env added [TZ]",
+		"allowed-env removed [HOME]",
+		"denied-env added [*_TOKEN]",
+		"denied-env removed [*_SECRET]",
+		"env-overrides added [ADD]",
+		"env-overrides removed [DROP]",
+		"env-overrides changed [CHANGE]",
+		"env-from-files added [API_KEY]",
+		"env-from-files changed [DB_PASSWORD]",
+	}, changes)
+
+	for _, change := range changes {
+		assert.NotContains(t, change, "secret", "env-override values must not be logged")
+	}
+
+	assert.Empty(t, diffRunnerConfigs(oldConfig, oldConfig))
+}
+
+func TestWatchRunnerConfigs(t *testing.T) {
+	workDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	t.Run("reloads on file change", func(t *testing.T) {
+		writeRunnersFile(t, configPath, workDir, `"a.js"`, "5681")
+		cfg := loadTestLauncherConfig(t, configPath)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go cfg.WatchRunnerConfigs(ctx, []string{"javascript"}, 5*time.Millisecond, nil)
+		time.Sleep(20 * time.Millisecond) // let watcher hash the original file
+
+		writeRunnersFile(t, configPath, workDir, `"b.js"`, "5681")
+
+		assert.Eventually(t, func() bool {
+			return cfg.RunnerConfig("javascript").Args[0] == "b.js"
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("reloads on signal", func(t *testing.T) {
+		writeRunnersFile(t, configPath, workDir, `"a.js"`, "5681")
+		cfg := loadTestLauncherConfig(t, configPath)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		reload := make(chan os.Signal, 1)
+		go cfg.WatchRunnerConfigs(ctx, []string{"javascript"}, time.Hour, reload)
+
+		writeRunnersFile(t, configPath, workDir, `"b.js"`, "5681")
+		reload <- syscall.SIGHUP
+
+		assert.Eventually(t, func() bool {
+			return cfg.RunnerConfig("javascript").Args[0] == "b.js"
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func envconfigLookuper(configPath string) envconfig.Lookuper {
+	return envconfig.MapLookuper(map[string]string{
+		"N8N_RUNNERS_AUTH_TOKEN":  "test-token",
+		"N8N_RUNNERS_CONFIG_PATH": configPath,
+	})
+}
@@ -0,0 +1,16 @@
+package config
+
+import _ "embed" // for the runners config file schema
+
+//go:generate go test -run TestRunnersSchema -update
+
+// runnersSchema is the JSON Schema of the runners config file, generated from
+// `RunnerConfig` by `TestRunnersSchema` with `-update`.
+//
+//go:embed runners.schema.json
+var runnersSchema []byte
+
+// RunnersSchema returns the JSON Schema of the runners config file.
+func RunnersSchema() []byte {
+	return runnersSchema
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"task-runner-launcher/internal/limits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update runners.schema.json")
+
+const schemaPath = "runners.schema.json"
+
+// TestRunnersSchema checks that the embedded schema matches the schema generated
+// from `RunnerConfig`. Run with `-update` to regenerate it.
+func TestRunnersSchema(t *testing.T) {
+	descriptions := fieldDescriptions(t, "config.go", "RunnerConfig")
+	for name, description := range fieldDescriptions(t, "../limits/limits.go", "Spec") {
+		descriptions[name] = description
+	}
+
+	generated := generateRunnersSchema(t, descriptions)
+
+	if *update {
+		require.NoError(t, os.WriteFile(schemaPath, generated, 0600))
+		return
+	}
+
+	assert.Equal(t, string(generated), string(RunnersSchema()), "%s is out of date, run `go generate ./internal/config`", schemaPath)
+}
+
+func TestSchemaConstraintsMatchFields(t *testing.T) {
+	fieldNames := append(jsonFieldNames(reflect.TypeOf(RunnerConfig{})), jsonFieldNames(reflect.TypeOf(limits.Spec{}))...)
+	for name := range fieldConstraints {
+		assert.Contains(t, fieldNames, name, "constraint for unknown field")
+	}
+}
+
+func generateRunnersSchema(t *testing.T, descriptions map[string]string) []byte {
+	t.Helper()
+
+	runnerSchema := structSchema(reflect.TypeOf(RunnerConfig{}), descriptions)
+
+	schema := map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "n8n task runner launcher config",
+		"description":          "Config file of the runners managed by the n8n task runner launcher.",
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"task-runners"},
+		"properties": map[string]any{
+			"task-runners": map[string]any{
+				"description": "Runners that the launcher may launch, selected by name on the command line.",
+				"type":        "array",
+				"minItems":    1,
+				"items":       runnerSchema,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	require.NoError(t, encoder.Encode(schema))
+
+	return buf.Bytes()
+}
+
+// structSchema returns the schema of a struct decoded from the config file,
+// with a property per field with a `json` tag.
+func structSchema(structType reflect.Type, descriptions map[string]string) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		property := typeSchema(field.Type, descriptions)
+		if description, ok := descriptions[name]; ok {
+			property["description"] = description
+		}
+		for keyword, value := range constraintSchema(fieldConstraints[name]) {
+			property[keyword] = value
+		}
+		if fieldConstraints[name].required {
+			required = append(required, name)
+		}
+
+		properties[name] = property
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// constraintSchema returns the schema keywords of the constraint.
+func constraintSchema(c constraint) map[string]any {
+	keywords := make(map[string]any)
+	if c.required {
+		keywords["minLength"] = 1
+	}
+	if c.pattern != nil {
+		keywords["pattern"] = c.pattern.String()
+	}
+	if len(c.reserved) > 0 {
+		keywords["not"] = map[string]any{"enum": slices.Sorted(maps.Keys(c.reserved))}
+	}
+	if c.minimum != nil && *c.minimum > 0 {
+		// unset, i.e. zero, is always allowed
+		keywords["anyOf"] = []any{map[string]any{"const": 0}, map[string]any{"minimum": *c.minimum}}
+	} else if c.minimum != nil {
+		keywords["minimum"] = *c.minimum
+	}
+
+	return keywords
+}
+
+func typeSchema(t reflect.Type, descriptions map[string]string) map[string]any {
+	if t == reflect.TypeOf(limits.Bytes(0)) {
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": 0, "pattern": limits.BytesPattern}
+	}
+	if t == reflect.TypeOf(Port("")) {
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": 1, "maximum": 65535}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), descriptions)
+	case reflect.Struct:
+		return structSchema(t, descriptions)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), descriptions)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), descriptions)}
+	default:
+		panic("unsupported config field type: " + t.String())
+	}
+}
+
+// fieldDescriptions returns the doc comments of the fields of the struct type
+// declared in the given file, by field name in the config file.
+func fieldDescriptions(t *testing.T, path, typeName string) map[string]string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	require.NoError(t, err)
+
+	descriptions := make(map[string]string)
+	ast.Inspect(file, func(node ast.Node) bool {
+		typeSpec, ok := node.(*ast.TypeSpec)
+		if !ok || typeSpec.Name.Name != typeName {
+			return true
+		}
+
+		for _, field := range typeSpec.Type.(*ast.StructType).Fields.List {
+			if field.Tag == nil || field.Doc == nil {
+				continue
+			}
+			tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+			name, _, _ := strings.Cut(tag.Get("json"), ",")
+			descriptions[name] = strings.Join(strings.Fields(field.Doc.Text()), " ")
+		}
+
+		return false
+	})
+
+	require.NotEmpty(t, descriptions, "no documented fields found for %s in %s", typeName, path)
+
+	return descriptions
+}
+
+func jsonFieldNames(structType reflect.Type) []string {
+	var names []string
+	for i := range structType.NumField() {
+		if name, _, _ := strings.Cut(structType.Field(i).Tag.Get("json"), ","); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
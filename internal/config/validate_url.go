@@ -22,3 +22,18 @@ func validateURL(urlStr string, urlName string) error {
 
 	return nil
 }
+
+// validateBrokerURI validates the task broker URI, which may also be a `unix://`
+// URI of a unix domain socket, e.g. `unix:///run/n8n/broker.sock`.
+func validateBrokerURI(urlStr string, urlName string) error {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Scheme != "unix" {
+		return validateURL(urlStr, urlName)
+	}
+
+	if u.Host != "" || u.Path == "" {
+		return fmt.Errorf("%s must have an absolute socket path, e.g. unix:///run/n8n/broker.sock", urlName)
+	}
+
+	return nil
+}
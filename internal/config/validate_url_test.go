@@ -62,3 +62,30 @@ func TestValidateURL(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateBrokerURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		errorMsg string
+	}{
+		{name: "http URL", url: "http://localhost:5679"},
+		{name: "https URL", url: "https://example.com"},
+		{name: "unix socket", url: "unix:///run/n8n/broker.sock"},
+		{name: "unix socket with host", url: "unix://run/n8n/broker.sock", errorMsg: "must have an absolute socket path"},
+		{name: "unix socket without path", url: "unix://", errorMsg: "must have an absolute socket path"},
+		{name: "other scheme", url: "ftp://example.com", errorMsg: "must use http:// or https:// scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBrokerURI(tt.url, "test_field")
+
+			if tt.errorMsg != "" {
+				assert.ErrorContains(t, err, tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
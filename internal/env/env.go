@@ -3,6 +3,7 @@ package env
 import (
 	"fmt"
 	"os"
+	"path"
 	"slices"
 	"sort"
 	"strings"
@@ -35,8 +36,9 @@ const (
 )
 
 // partitionByAllowlist divides the current env vars into those included in and
-// excluded from the allowlist.
-func partitionByAllowlist(allowlist []string) (included, excluded []string) {
+// excluded from the allowlist. An env var matching the denylist is excluded even
+// if it matches the allowlist. Both lists may contain glob patterns, e.g. `OTEL_*`.
+func partitionByAllowlist(allowlist, denylist []string, logger *logs.Logger) (included, excluded []string) {
 	for _, env := range os.Environ() {
 		parts := strings.SplitN(env, "=", 2)
 		if len(parts) != 2 {
@@ -44,13 +46,19 @@ func partitionByAllowlist(allowlist []string) (included, excluded []string) {
 		}
 
 		key := parts[0]
-		isAllowed := false
-		if slices.Contains(allowlist, key) {
+		deniedBy, isDenied := MatchingPattern(denylist, key)
+		allowedBy, isAllowed := MatchingPattern(allowlist, key)
+
+		switch {
+		case isDenied:
+			excluded = append(excluded, env)
+			logger.Debugf("Env var %s: excluded, matches denied-env %q", key, deniedBy)
+		case isAllowed:
 			included = append(included, env)
-			isAllowed = true
-		}
-		if !isAllowed {
+			logger.Debugf("Env var %s: included, matches allowed-env %q", key, allowedBy)
+		default:
 			excluded = append(excluded, env)
+			logger.Debugf("Env var %s: excluded, matches no allowed-env", key)
 		}
 	}
 
@@ -61,6 +69,19 @@ func partitionByAllowlist(allowlist []string) (included, excluded []string) {
 	return included, excluded
 }
 
+// MatchingPattern returns the first of the glob patterns, as accepted by
+// `path.Match`, that matches the env var name.
+func MatchingPattern(patterns []string, key string) (string, bool) {
+	for _, pattern := range patterns {
+		// invalid patterns are rejected on config load
+		if matched, _ := path.Match(pattern, key); matched {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
 // keys returns the keys of the environment variables.
 func keys(env []string) []string {
 	keys := make([]string, len(env))
@@ -90,7 +111,7 @@ func checkLegacyBehavior(runnerConfig *config.RunnerConfig) {
 		EnvVarTaskTimeout,
 	}
 	for _, timeoutEnvVar := range timeoutEnvVars {
-		hasInAllowed := slices.Contains(runnerConfig.AllowedEnv, timeoutEnvVar)
+		_, hasInAllowed := MatchingPattern(runnerConfig.AllowedEnv, timeoutEnvVar)
 		if !hasInAllowed {
 			logs.Warnf("DEPRECATION WARNING: %s will no longer be automatically passed to runners in a future version. Please add this env var to 'allowed-env' or use 'env-overrides' in your task runner config to maintain current behavior.", timeoutEnvVar)
 		}
@@ -113,13 +134,13 @@ func PrepareRunnerEnv(baseConfig *config.BaseConfig, runnerConfig *config.Runner
 	defaultEnvs := []string{"LANG", "PATH", "TZ", "TERM"}
 	allowedEnvs := append(defaultEnvs, runnerConfig.AllowedEnv...)
 
-	includedEnvs, _ := partitionByAllowlist(allowedEnvs)
+	includedEnvs, _ := partitionByAllowlist(allowedEnvs, runnerConfig.DeniedEnv, logger)
 
 	runnerEnv := includedEnvs
 	for _, envVar := range requiredRuntimeEnvVars {
 		runnerEnv = Clear(runnerEnv, envVar)
 	}
-	runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", EnvVarTaskBrokerURI, baseConfig.TaskBrokerURI))
+	runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", EnvVarTaskBrokerURI, baseConfig.BrokerURIForRunners()))
 	runnerEnv = append(runnerEnv, fmt.Sprintf("%s=true", EnvVarHealthCheckServerEnabled))
 	runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", EnvVarHealthCheckServerPort, runnerConfig.HealthCheckServerPort))
 
@@ -136,7 +157,55 @@ func PrepareRunnerEnv(baseConfig *config.BaseConfig, runnerConfig *config.Runner
 		runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", key, value))
 	}
 
+	for _, key := range sortedKeys(runnerConfig.EnvFromFiles) {
+		if slices.Contains(requiredRuntimeEnvVars, key) {
+			logger.Warnf("Disregarded env-from-files for required runtime variable: %s", key)
+			continue
+		}
+		runnerEnv = Clear(runnerEnv, key)
+	}
+
 	logger.Debugf("Env vars to pass to runner: %v", keys(runnerEnv))
 
 	return runnerEnv
 }
+
+// AddEnvFromFiles adds to the runner env the runner's `env-from-files` env vars,
+// reading every file anew so that rotated secrets are picked up. Only the env
+// var names are logged, never the values.
+func AddEnvFromFiles(runnerEnv []string, runnerConfig *config.RunnerConfig, logger *logs.Logger) ([]string, error) {
+	var added []string
+	for _, key := range sortedKeys(runnerConfig.EnvFromFiles) {
+		if slices.Contains(requiredRuntimeEnvVars, key) {
+			continue // disregarded on preparing runner env
+		}
+
+		filePath := runnerConfig.EnvFromFiles[key]
+		// #nosec G304 -- filePath is controlled by system administrator via config file
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env-from-files.%s: %w", key, err)
+		}
+
+		runnerEnv = Clear(runnerEnv, key)
+		runnerEnv = append(runnerEnv, fmt.Sprintf("%s=%s", key, strings.TrimRight(string(content), "\n\r")))
+		added = append(added, key)
+	}
+
+	if len(added) > 0 {
+		logger.Debugf("Env vars read from files: %v", added)
+	}
+
+	return runnerEnv, nil
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -1,7 +1,9 @@
 package env
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"sort"
@@ -10,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPartitionByAllowlist(t *testing.T) {
@@ -17,6 +20,7 @@ func TestPartitionByAllowlist(t *testing.T) {
 		name            string
 		envVars         map[string]string
 		allowList       []string
+		denyList        []string
 		expectedInclude []string
 		expectedExclude []string
 	}{
@@ -52,6 +56,35 @@ func TestPartitionByAllowlist(t *testing.T) {
 			expectedInclude: nil,
 			expectedExclude: []string{"FOO=bar"},
 		},
+		{
+			name: "includes env vars matching glob patterns",
+			envVars: map[string]string{
+				"OTEL_SERVICE_NAME":  "runner",
+				"OTEL_EXPORTER_HOST": "collector",
+				"NODE_ENV":           "production",
+				"HOTEL":              "value",
+			},
+			allowList: []string{"OTEL_*", "NODE_?NV"},
+			expectedInclude: []string{
+				"NODE_ENV=production",
+				"OTEL_EXPORTER_HOST=collector",
+				"OTEL_SERVICE_NAME=runner",
+			},
+			expectedExclude: []string{"HOTEL=value"},
+		},
+		{
+			name: "denylist wins over allowlist",
+			envVars: map[string]string{
+				"OTEL_SERVICE_NAME":  "runner",
+				"OTEL_EXPORTER_AUTH": "secret",
+				"PATH":               "/usr/bin",
+				"AWS_SECRET":         "secret",
+			},
+			allowList:       []string{"OTEL_*", "PATH", "AWS_SECRET"},
+			denyList:        []string{"*_AUTH", "AWS_*"},
+			expectedInclude: []string{"OTEL_SERVICE_NAME=runner", "PATH=/usr/bin"},
+			expectedExclude: []string{"AWS_SECRET=secret", "OTEL_EXPORTER_AUTH=secret"},
+		},
 		{
 			name:            "returns empty slices when env vars is empty",
 			envVars:         map[string]string{},
@@ -68,7 +101,7 @@ func TestPartitionByAllowlist(t *testing.T) {
 				os.Setenv(k, v)
 			}
 
-			included, excluded := partitionByAllowlist(tt.allowList)
+			included, excluded := partitionByAllowlist(tt.allowList, tt.denyList, logs.NewLogger(logs.InfoLevel, ""))
 
 			if tt.expectedInclude == nil {
 				assert.Empty(t, included)
@@ -85,6 +118,80 @@ func TestPartitionByAllowlist(t *testing.T) {
 	}
 }
 
+func TestPartitionByAllowlistLogsDecisions(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OTEL_SERVICE_NAME", "runner")
+	os.Setenv("OTEL_EXPORTER_AUTH", "secret-value")
+	os.Setenv("OTHER", "other-value")
+
+	logger, logged := newCapturingLogger(t)
+
+	partitionByAllowlist([]string{"OTEL_*"}, []string{"*_AUTH"}, logger)
+
+	output := logged()
+	assert.Contains(t, output, `Env var OTEL_SERVICE_NAME: included, matches allowed-env "OTEL_*"`)
+	assert.Contains(t, output, `Env var OTEL_EXPORTER_AUTH: excluded, matches denied-env "*_AUTH"`)
+	assert.Contains(t, output, "Env var OTHER: excluded, matches no allowed-env")
+	assert.NotContains(t, output, "secret-value")
+}
+
+func TestAddEnvFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte("first-secret\n"), 0600))
+
+	runnerConfig := &config.RunnerConfig{
+		EnvFromFiles: map[string]string{
+			"DB_PASSWORD":       secretPath,
+			EnvVarGrantToken:    secretPath,
+			"ALSO_IN_ALLOWLIST": secretPath,
+		},
+	}
+	baseEnv := []string{"PATH=/usr/bin", "ALSO_IN_ALLOWLIST=from-env"}
+
+	logger, logged := newCapturingLogger(t)
+
+	runnerEnv, err := AddEnvFromFiles(baseEnv, runnerConfig, logger)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"PATH=/usr/bin", "ALSO_IN_ALLOWLIST=first-secret", "DB_PASSWORD=first-secret"}, runnerEnv)
+	assert.Equal(t, []string{"PATH=/usr/bin", "ALSO_IN_ALLOWLIST=from-env"}, baseEnv, "base env must not be modified")
+
+	// rotated secret is picked up on next launch
+	require.NoError(t, os.WriteFile(secretPath, []byte("rotated-secret"), 0600))
+	runnerEnv, err = AddEnvFromFiles(baseEnv, runnerConfig, logger)
+	require.NoError(t, err)
+	assert.Contains(t, runnerEnv, "DB_PASSWORD=rotated-secret")
+
+	output := logged()
+	assert.Contains(t, output, "Env vars read from files: [ALSO_IN_ALLOWLIST DB_PASSWORD]")
+	assert.NotContains(t, output, "secret")
+
+	runnerConfig.EnvFromFiles["MISSING"] = filepath.Join(dir, "missing")
+	_, err = AddEnvFromFiles(baseEnv, runnerConfig, logger)
+	assert.ErrorContains(t, err, "failed to read env-from-files.MISSING")
+}
+
+// newCapturingLogger returns a debug logger and a func that returns everything
+// logged so far. The func may be called only once.
+func newCapturingLogger(t *testing.T) (*logs.Logger, func() string) {
+	t.Helper()
+
+	// logger writes to stdout at the time of its creation
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	logger := logs.NewLogger(logs.DebugLevel, "")
+	os.Stdout = stdout
+
+	return logger, func() string {
+		w.Close()
+		logged, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(logged)
+	}
+}
+
 func TestKeys(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -238,6 +345,33 @@ func TestPrepareRunnerEnv(t *testing.T) {
 				"PATH=/usr/bin",
 			},
 		},
+		{
+			name: "passes runner broker URI instead of unix broker URI",
+			launcherConfig: &config.LauncherConfig{
+				BaseConfig: &config.BaseConfig{
+					AutoShutdownTimeout: "15",
+					TaskTimeout:         "60",
+					TaskBrokerURI:       "unix:///run/n8n/broker.sock",
+					RunnerBrokerURI:     "http://localhost:5679",
+				},
+				RunnerConfigs: map[string]*config.RunnerConfig{
+					"javascript": {
+						HealthCheckServerPort: "5681",
+					},
+				},
+			},
+			envSetup: map[string]string{
+				"PATH": "/usr/bin",
+			},
+			expected: []string{
+				"N8N_RUNNERS_AUTO_SHUTDOWN_TIMEOUT=15",
+				"N8N_RUNNERS_HEALTH_CHECK_SERVER_ENABLED=true",
+				"N8N_RUNNERS_HEALTH_CHECK_SERVER_PORT=5681",
+				"N8N_RUNNERS_TASK_BROKER_URI=http://localhost:5679",
+				"N8N_RUNNERS_TASK_TIMEOUT=60",
+				"PATH=/usr/bin",
+			},
+		},
 		{
 			name: "handles custom auto-shutdown timeout",
 			launcherConfig: &config.LauncherConfig{
@@ -371,7 +505,7 @@ func TestPrepareRunnerEnv(t *testing.T) {
 func TestRunnerPortPassedToEnv(t *testing.T) {
 	tests := []struct {
 		name        string
-		port        string
+		port        config.Port
 		expectedEnv string
 	}{
 		{
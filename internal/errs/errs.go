@@ -14,4 +14,7 @@ var (
 
 	// ErrNegativeAutoShutdownTimeout is returned when the auto shutdown timeout is a negative integer.
 	ErrNegativeAutoShutdownTimeout = errors.New("negative auto-shutdown timeout - N8N_RUNNERS_AUTO_SHUTDOWN_TIMEOUT must be >= 0")
+
+	// ErrNegativeShutdownGracePeriod is returned when the shutdown grace period is a negative integer.
+	ErrNegativeShutdownGracePeriod = errors.New("negative shutdown grace period - N8N_RUNNERS_LAUNCHER_SHUTDOWN_GRACE_PERIOD must be >= 0")
 )
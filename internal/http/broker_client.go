@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Default timeouts of a single request to the task broker, per call type.
+const (
+	DefaultReadinessCheckTimeout  = 5 * time.Second
+	DefaultGrantTokenFetchTimeout = 10 * time.Second
+)
+
+// userAgentProduct is the product name in the user agent sent to the task broker.
+const userAgentProduct = "n8n-task-runner-launcher"
+
+// BrokerClientConfig holds the configuration for a broker client.
+type BrokerClientConfig struct {
+	// URI is the URI of the task broker server.
+	URI string
+
+	// Version is the launcher version, sent in the user agent.
+	Version string
+
+	// RunnerType is the type of the runner that the client sends requests for,
+	// sent in the user agent.
+	RunnerType string
+
+	// RunnerName is the name of the runner that the client sends requests for,
+	// to label metrics.
+	RunnerName string
+
+	// ReadinessCheckTimeout is the timeout of a single readiness check request.
+	// Default: `DefaultReadinessCheckTimeout`.
+	ReadinessCheckTimeout time.Duration
+
+	// GrantTokenFetchTimeout is the timeout of a single grant token request.
+	// Default: `DefaultGrantTokenFetchTimeout`.
+	GrantTokenFetchTimeout time.Duration
+}
+
+// BrokerClient sends HTTP requests to the task broker on behalf of a runner.
+// All broker clients share a transport, so connections are reused across
+// requests and runners. Requests use the broker TLS config, if any, and the
+// proxy set by `HTTPS_PROXY` or `HTTP_PROXY`, unless excluded by `NO_PROXY`.
+// For a `unix://` URI, requests go over the unix domain socket instead.
+type BrokerClient struct {
+	cfg     BrokerClientConfig
+	baseURL string // base URL of requests, i.e. the URI unless a `unix://` URI
+	client  *http.Client
+}
+
+func NewBrokerClient(cfg BrokerClientConfig) *BrokerClient {
+	if cfg.ReadinessCheckTimeout <= 0 {
+		cfg.ReadinessCheckTimeout = DefaultReadinessCheckTimeout
+	}
+
+	if cfg.GrantTokenFetchTimeout <= 0 {
+		cfg.GrantTokenFetchTimeout = DefaultGrantTokenFetchTimeout
+	}
+
+	if socketPath, ok := UnixSocketPath(cfg.URI); ok {
+		return &BrokerClient{cfg: cfg, baseURL: unixSocketBaseURL, client: &http.Client{Transport: unixTransport(socketPath)}}
+	}
+
+	brokerTLSMu.RLock()
+	defer brokerTLSMu.RUnlock()
+
+	return &BrokerClient{cfg: cfg, baseURL: cfg.URI, client: &http.Client{Transport: brokerTransport}}
+}
+
+// URI returns the URI of the task broker server.
+func (c *BrokerClient) URI() string {
+	return c.cfg.URI
+}
+
+// UserAgent returns the user agent sent with every request to the task broker,
+// e.g. `n8n-task-runner-launcher/1.2.0 (javascript)`.
+func (c *BrokerClient) UserAgent() string {
+	version := c.cfg.Version
+	if version == "" {
+		version = "unknown"
+	}
+
+	if c.cfg.RunnerType == "" {
+		return fmt.Sprintf("%s/%s", userAgentProduct, version)
+	}
+
+	return fmt.Sprintf("%s/%s (%s)", userAgentProduct, version, c.cfg.RunnerType)
+}
+
+// newRequest returns a request to the given path of the task broker, with a
+// context that times out after the given timeout, to be released by calling the
+// returned cancel func once done with the response.
+func (c *BrokerClient) newRequest(ctx context.Context, method, path string, body io.Reader, timeout time.Duration) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	req.Header.Set("User-Agent", c.UserAgent())
+
+	return req, cancel, nil
+}
+
+// BrokerProxy returns the proxy for a connection to the task broker, as set by
+// `HTTPS_PROXY` or `HTTP_PROXY`, unless excluded by `NO_PROXY`.
+func BrokerProxy(req *http.Request) (*url.URL, error) {
+	return http.ProxyFromEnvironment(req)
+}
+
+// newBrokerTransport returns a transport for requests to the task broker with
+// the given TLS config, which may be nil for the defaults.
+func newBrokerTransport(tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = BrokerProxy
+	transport.TLSClientConfig = tlsConfig.Clone()
+
+	return transport
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerClientUserAgent(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      BrokerClientConfig
+		expected string
+	}{
+		{
+			name:     "version and runner type",
+			cfg:      BrokerClientConfig{Version: "1.2.0", RunnerType: "javascript"},
+			expected: "n8n-task-runner-launcher/1.2.0 (javascript)",
+		},
+		{
+			name:     "no runner type",
+			cfg:      BrokerClientConfig{Version: "1.2.0"},
+			expected: "n8n-task-runner-launcher/1.2.0",
+		},
+		{
+			name:     "no version",
+			cfg:      BrokerClientConfig{RunnerType: "python"},
+			expected: "n8n-task-runner-launcher/unknown (python)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewBrokerClient(tt.cfg).UserAgent())
+		})
+	}
+}
+
+func TestBrokerClientRequests(t *testing.T) {
+	var userAgents []string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		if r.URL.Path == "/runners/auth" {
+			_, _ = w.Write([]byte(`{"data":{"token":"grant-token"}}`))
+		}
+	}))
+	var conns atomic.Int32
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client := NewBrokerClient(BrokerClientConfig{URI: srv.URL, Version: "1.2.0", RunnerType: "javascript"})
+
+	for range 3 {
+		statusCode, err := client.sendHealthRequest(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+	}
+
+	token, err := client.sendGrantTokenRequest(context.Background(), "auth-token")
+	require.NoError(t, err)
+	assert.Equal(t, "grant-token", token)
+
+	assert.Equal(t, int32(1), conns.Load(), "connection should be reused across requests")
+	for _, userAgent := range userAgents {
+		assert.Equal(t, "n8n-task-runner-launcher/1.2.0 (javascript)", userAgent)
+	}
+}
+
+func TestBrokerClientTimeouts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer srv.Close()
+
+	client := NewBrokerClient(BrokerClientConfig{
+		URI:                    srv.URL,
+		ReadinessCheckTimeout:  20 * time.Millisecond,
+		GrantTokenFetchTimeout: 20 * time.Millisecond,
+	})
+
+	_, err := client.sendHealthRequest(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.sendGrantTokenRequest(context.Background(), "auth-token")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	defaultClient := NewBrokerClient(BrokerClientConfig{URI: srv.URL})
+	assert.Equal(t, DefaultReadinessCheckTimeout, defaultClient.cfg.ReadinessCheckTimeout)
+	assert.Equal(t, DefaultGrantTokenFetchTimeout, defaultClient.cfg.GrantTokenFetchTimeout)
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"crypto/tls"
+	"sync"
+)
+
+var (
+	brokerTLSMu sync.RWMutex
+
+	// brokerTLS is the TLS config for all connections to the task broker, or nil
+	// to use the defaults.
+	brokerTLS *tls.Config
+
+	// brokerTransport is the transport for all HTTP requests to the task broker.
+	brokerTransport = newBrokerTransport(nil)
+)
+
+// SetBrokerTLSConfig sets the TLS config for all HTTP requests and websocket
+// connections to the task broker. A nil config restores the defaults.
+func SetBrokerTLSConfig(tlsConfig *tls.Config) {
+	brokerTLSMu.Lock()
+	defer brokerTLSMu.Unlock()
+
+	brokerTLS = tlsConfig
+	brokerTransport = newBrokerTransport(tlsConfig)
+}
+
+// BrokerTLSConfig returns a copy of the TLS config for connections to the task
+// broker, or nil if none is set.
+func BrokerTLSConfig() *tls.Config {
+	brokerTLSMu.RLock()
+	defer brokerTLSMu.RUnlock()
+
+	return brokerTLS.Clone()
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"task-runner-launcher/internal/tlsconfig"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes the PEM block of the given type to a file and returns its path.
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "file.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+
+	return path
+}
+
+func TestBrokerTLS(t *testing.T) {
+	t.Cleanup(func() { SetBrokerTLSConfig(nil) })
+
+	var clientCerts int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientCerts = len(r.TLS.PeerCertificates)
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// server cert is not trusted by default
+	_, err := NewBrokerClient(BrokerClientConfig{URI: srv.URL}).sendHealthRequest(context.Background())
+	require.Error(t, err)
+
+	// present the server's own cert as client cert
+	serverCert := srv.TLS.Certificates[0]
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	require.NoError(t, err)
+
+	caFile := writePEM(t, "CERTIFICATE", srv.Certificate().Raw)
+	certFile := writePEM(t, "CERTIFICATE", serverCert.Certificate[0])
+	keyFile := writePEM(t, "PRIVATE KEY", keyDER)
+
+	tlsConfig, err := tlsconfig.New(caFile, certFile, keyFile, "example.com")
+	require.NoError(t, err)
+	SetBrokerTLSConfig(tlsConfig)
+
+	statusCode, err := NewBrokerClient(BrokerClientConfig{URI: srv.URL}).sendHealthRequest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, 1, clientCerts)
+
+	// server name overrides the host of the broker URI
+	tlsConfig, err = tlsconfig.New(caFile, "", "", "broker.invalid")
+	require.NoError(t, err)
+	SetBrokerTLSConfig(tlsConfig)
+
+	_, err = NewBrokerClient(BrokerClientConfig{URI: srv.URL}).sendHealthRequest(context.Background())
+	assert.ErrorContains(t, err, "broker.invalid")
+}
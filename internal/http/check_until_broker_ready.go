@@ -1,49 +1,58 @@
 package http
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"task-runner-launcher/internal/logs"
 	"task-runner-launcher/internal/retry"
-	"time"
 )
 
-func sendHealthRequest(taskBrokerURI string) (*http.Response, error) {
-	url := fmt.Sprintf("%s/healthz", taskBrokerURI)
+// OperationReadinessCheck is the retry operation name for the task broker
+// readiness check.
+const OperationReadinessCheck = "readiness-check"
 
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+// sendHealthRequest sends a request to the task broker's health endpoint and
+// returns the status code of the response.
+func (c *BrokerClient) sendHealthRequest(ctx context.Context) (int, error) {
+	req, cancel, err := c.newRequest(ctx, "GET", "/healthz", nil, c.cfg.ReadinessCheckTimeout)
+	if err != nil {
+		return 0, err
 	}
+	defer cancel()
 
-	req, err := http.NewRequest("GET", url, nil)
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body) // drain body to reuse connection
 
-	return client.Do(req)
+	return resp.StatusCode, nil
 }
 
 // CheckUntilBrokerReady checks forever until the task broker is ready, i.e.
 // In case of long-running migrations, readiness may take a long time.
-// Returns nil when ready.
-func CheckUntilBrokerReady(taskBrokerURI string, logger *logs.Logger) error {
+// Returns nil when ready, or the context's error if cancelled while waiting.
+func (c *BrokerClient) CheckUntilBrokerReady(ctx context.Context, logger *logs.Logger) error {
 	logger.Info("Waiting for task broker to be ready...")
 
 	healthCheck := func() (string, error) {
-		resp, err := sendHealthRequest(taskBrokerURI)
+		statusCode, err := c.sendHealthRequest(ctx)
 		if err != nil {
 			return "", fmt.Errorf("task broker readiness check failed with error: %w", err)
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("task broker readiness check failed with status code: %d", resp.StatusCode)
+		if statusCode != http.StatusOK {
+			return "", fmt.Errorf("task broker readiness check failed with status code: %d", statusCode)
 		}
 
 		return "", nil
 	}
 
-	if _, err := retry.UnlimitedRetry("readiness-check", healthCheck); err != nil {
+	if _, err := retry.UnlimitedRetryWithContext(ctx, OperationReadinessCheck, healthCheck); err != nil {
 		return err
 	}
 
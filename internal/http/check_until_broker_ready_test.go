@@ -45,7 +45,7 @@ func TestCheckUntilBrokerReadyHappyPath(t *testing.T) {
 			done := make(chan error)
 			go func() {
 				logger := logs.NewLogger(logs.InfoLevel, "")
-				done <- CheckUntilBrokerReady(srv.URL, logger)
+				done <- NewBrokerClient(BrokerClientConfig{URI: srv.URL}).CheckUntilBrokerReady(context.Background(), logger)
 			}()
 
 			select {
@@ -100,7 +100,7 @@ func TestCheckUntilBrokerReadyErrors(t *testing.T) {
 			brokerUnexpectedlyReady := make(chan error)
 			go func() {
 				logger := logs.NewLogger(logs.InfoLevel, "")
-				brokerUnexpectedlyReady <- CheckUntilBrokerReady(srv.URL, logger)
+				brokerUnexpectedlyReady <- NewBrokerClient(BrokerClientConfig{URI: srv.URL}).CheckUntilBrokerReady(context.Background(), logger)
 			}()
 
 			select {
@@ -145,16 +145,37 @@ func TestSendReadinessRequest(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			resp, err := sendHealthRequest(srv.URL)
+			statusCode, err := NewBrokerClient(BrokerClientConfig{URI: srv.URL}).sendHealthRequest(context.Background())
 
 			if !tt.expectedError {
 				require.NoError(t, err, "Unexpected error making request")
-				require.NotNil(t, resp, "Response should not be nil")
-				defer resp.Body.Close()
-				assert.Equal(t, tt.serverResponse, resp.StatusCode, "Unexpected status code")
+				assert.Equal(t, tt.serverResponse, statusCode, "Unexpected status code")
 			} else {
 				assert.Error(t, err, "Expected an error")
 			}
 		})
 	}
 }
+
+func TestCheckUntilBrokerReadyContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		logger := logs.NewLogger(logs.InfoLevel, "")
+		done <- NewBrokerClient(BrokerClientConfig{URI: srv.URL}).CheckUntilBrokerReady(ctx, logger)
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "Expected context deadline error")
+	case <-time.After(time.Second):
+		t.Error("CheckUntilBrokerReady did not return after context cancellation")
+	}
+}
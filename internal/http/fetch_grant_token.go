@@ -2,35 +2,38 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"task-runner-launcher/internal/metrics"
 	"task-runner-launcher/internal/retry"
 )
 
+// OperationGrantTokenFetch is the retry operation name for grant token fetches.
+const OperationGrantTokenFetch = "grant-token-fetch"
+
 type grantTokenResponse struct {
 	Data struct {
 		Token string `json:"token"`
 	} `json:"data"`
 }
 
-func sendGrantTokenRequest(taskBrokerServerURI, authToken string) (string, error) {
-	url := fmt.Sprintf("%s/runners/auth", taskBrokerServerURI)
-
+func (c *BrokerClient) sendGrantTokenRequest(ctx context.Context, authToken string) (string, error) {
 	payload := map[string]string{"token": authToken}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal grant token request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+	req, cancel, err := c.newRequest(ctx, "POST", "/runners/auth", bytes.NewReader(payloadBytes), c.cfg.GrantTokenFetchTimeout)
 	if err != nil {
 		return "", fmt.Errorf("failed to create grant token request: %w", err)
 	}
+	defer cancel()
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -50,17 +53,28 @@ func sendGrantTokenRequest(taskBrokerServerURI, authToken string) (string, error
 
 // FetchGrantToken exchanges the launcher's auth token for a single-use grant
 // token from the task broker. In case the task broker is temporarily
-// unavailable, this exchange is retried a limited number of times.
-func FetchGrantToken(taskBrokerServerURI, authToken string) (string, error) {
+// unavailable, this exchange is retried a limited number of times, or until
+// the context is cancelled.
+func (c *BrokerClient) FetchGrantToken(ctx context.Context, authToken string) (string, error) {
+	attempt := 0
 	grantTokenFetch := func() (string, error) {
-		token, err := sendGrantTokenRequest(taskBrokerServerURI, authToken)
+		attempt++
+		if attempt > 1 {
+			metrics.GrantTokenFetchRetries.Inc(c.cfg.RunnerName)
+		}
+
+		token, err := c.sendGrantTokenRequest(ctx, authToken)
 		if err != nil {
 			return "", fmt.Errorf("failed to fetch grant token: %w", err)
 		}
 		return token, nil
 	}
 
-	token, err := retry.LimitedRetry("grant-token-fetch", grantTokenFetch)
+	token, err := retry.LimitedRetryWithContext(ctx, OperationGrantTokenFetch, grantTokenFetch)
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", ctxErr
+	}
 
 	if err != nil {
 		return "", fmt.Errorf("exhausted retries to fetch grant token: %w", err)
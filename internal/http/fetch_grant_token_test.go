@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -93,7 +94,7 @@ func TestFetchGrantToken(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			token, err := FetchGrantToken(srv.URL, tt.authToken)
+			token, err := NewBrokerClient(BrokerClientConfig{URI: srv.URL}).FetchGrantToken(context.Background(), tt.authToken)
 
 			if tt.wantErr {
 				assert.Error(t, err, "Expected an error")
@@ -108,7 +109,7 @@ func TestFetchGrantToken(t *testing.T) {
 }
 
 func TestFetchGrantTokenInvalidURL(t *testing.T) {
-	token, err := FetchGrantToken("not-a-valid-url", "test-token")
+	token, err := NewBrokerClient(BrokerClientConfig{URI: "not-a-valid-url"}).FetchGrantToken(context.Background(), "test-token")
 
 	assert.Error(t, err, "Expected error for invalid URL")
 	assert.Empty(t, token, "Token should be empty for invalid URL")
@@ -133,7 +134,7 @@ func TestFetchGrantTokenRetry(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	token, err := FetchGrantToken(srv.URL, "test-token")
+	token, err := NewBrokerClient(BrokerClientConfig{URI: srv.URL}).FetchGrantToken(context.Background(), "test-token")
 
 	assert.NoError(t, err, "Unexpected error after retry")
 	assert.NotEmpty(t, token, "Expected non-empty token after retry")
@@ -143,9 +144,24 @@ func TestFetchGrantTokenRetry(t *testing.T) {
 func TestFetchGrantTokenConnectionFailure(t *testing.T) {
 	invalidServerURL := "http://localhost:1"
 
-	token, err := FetchGrantToken(invalidServerURL, "test-token")
+	token, err := NewBrokerClient(BrokerClientConfig{URI: invalidServerURL}).FetchGrantToken(context.Background(), "test-token")
 
 	assert.Error(t, err, "Expected error for connection failure")
 	assert.Contains(t, err.Error(), "connection refused", "Unexpected error message")
 	assert.Empty(t, token, "Token should be empty for failed connection")
 }
+
+func TestFetchGrantTokenContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	token, err := NewBrokerClient(BrokerClientConfig{URI: srv.URL}).FetchGrantToken(ctx, "test-token")
+
+	assert.ErrorIs(t, err, context.Canceled, "Expected context cancellation error")
+	assert.Empty(t, token, "Token should be empty on cancellation")
+}
@@ -6,17 +6,20 @@ import (
 	"net"
 	"net/http"
 	"task-runner-launcher/internal/logs"
+	"task-runner-launcher/internal/metrics"
 	"time"
 )
 
 const (
 	healthCheckPath = "/healthz"
+	readinessPath   = "/readyz"
+	metricsPath     = "/metrics"
 	readTimeout     = 1 * time.Second
 	writeTimeout    = 1 * time.Second
 )
 
 // InitHealthCheckServer creates and starts the launcher's health check server
-// exposing `/healthz` at the given port, running in a goroutine.
+// exposing `/healthz`, `/readyz` and `/metrics` at the given port, running in a goroutine.
 func InitHealthCheckServer(port string) {
 	srv := newHealthCheckServer(port)
 	logs.Infof("Starting launcher's health check server at port %s", port)
@@ -37,6 +40,8 @@ func InitHealthCheckServer(port string) {
 func newHealthCheckServer(port string) *http.Server {
 	mux := http.NewServeMux()
 	mux.HandleFunc(healthCheckPath, handleHealthCheck)
+	mux.HandleFunc(readinessPath, handleReadinessCheck)
+	mux.Handle(metricsPath, metrics.Handler())
 
 	return &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
@@ -94,3 +94,15 @@ func TestNewHealthCheckServer(t *testing.T) {
 	assert.Equal(t, readTimeout, server.ReadTimeout, "unexpected read timeout")
 	assert.Equal(t, writeTimeout, server.WriteTimeout, "unexpected write timeout")
 }
+
+func TestHealthCheckServerExposesMetrics(t *testing.T) {
+	server := newHealthCheckServer("5680")
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	server.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "unexpected status code")
+	assert.Contains(t, w.Body.String(), "n8n_launcher_runner_launches_total", "expected metrics in response")
+}
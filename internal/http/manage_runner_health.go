@@ -3,10 +3,12 @@ package http
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os/exec"
 	"sync"
 	"task-runner-launcher/internal/logs"
+	"task-runner-launcher/internal/metrics"
 	"time"
 )
 
@@ -40,6 +42,18 @@ const (
 	StatusMonitoringCancelled
 )
 
+// runnerHealthClient is the client for all health check requests to runners,
+// reusing connections across requests. Runners are local, so requests to them
+// never go through a proxy.
+var runnerHealthClient = &http.Client{Transport: newRunnerTransport()}
+
+func newRunnerTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+
+	return transport
+}
+
 // healthCheckResult contains the result of health monitoring
 type healthCheckResult struct {
 	Status HealthStatus
@@ -50,16 +64,22 @@ type healthCheckResult struct {
 func sendRunnerHealthCheckRequest(runnerServerURI string) error {
 	url := fmt.Sprintf("%s/healthz", runnerServerURI)
 
-	client := &http.Client{
-		Timeout: healthCheckTimeout,
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request to runner: %w", err)
 	}
 
-	resp, err := client.Get(url)
+	resp, err := runnerHealthClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send health check request to runner: %w", err)
 	}
 	defer resp.Body.Close()
 
+	_, _ = io.Copy(io.Discard, resp.Body) // drain body to reuse connection
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("runner health check returned status code %d", resp.StatusCode)
 	}
@@ -70,6 +90,8 @@ func sendRunnerHealthCheckRequest(runnerServerURI string) error {
 func monitorRunnerHealth(
 	ctx context.Context,
 	runnerServerURI string,
+	runnerName string,
+	slot int,
 	wg *sync.WaitGroup,
 	logger *logs.Logger,
 ) chan healthCheckResult {
@@ -81,7 +103,13 @@ func monitorRunnerHealth(
 		defer wg.Done()
 		defer close(resultChan)
 
-		time.Sleep(initialDelay)
+		select {
+		case <-ctx.Done():
+			logger.Debug("Stopped monitoring runner health")
+			resultChan <- healthCheckResult{Status: StatusMonitoringCancelled}
+			return
+		case <-time.After(initialDelay):
+		}
 
 		failureCount := 0
 		ticker := time.NewTicker(healthCheckInterval)
@@ -97,6 +125,8 @@ func monitorRunnerHealth(
 			case <-ticker.C:
 				if err := sendRunnerHealthCheckRequest(runnerServerURI); err != nil {
 					failureCount++
+					metrics.HealthCheckFailures.Inc(runnerName)
+					SetSlotState(runnerName, slot, StateRunnerUnhealthy)
 					logger.Warnf("Found runner unresponsive (%d/%d)", failureCount, healthCheckMaxFailures)
 					if failureCount >= healthCheckMaxFailures {
 						resultChan <- healthCheckResult{Status: StatusUnhealthy}
@@ -104,6 +134,7 @@ func monitorRunnerHealth(
 					}
 				} else {
 					logger.Debug("Found runner healthy")
+					SetSlotState(runnerName, slot, StateRunnerRunning)
 					failureCount = 0
 				}
 			}
@@ -113,15 +144,18 @@ func monitorRunnerHealth(
 	return resultChan
 }
 
-// ManageRunnerHealth monitors runner health and terminates it if unhealthy.
+// ManageRunnerHealth monitors the health of the runner in the given slot of the
+// given runner's pool, and terminates it if unhealthy.
 func ManageRunnerHealth(
 	ctx context.Context,
 	cmd *exec.Cmd,
 	runnerServerURI string,
+	runnerName string,
+	slot int,
 	wg *sync.WaitGroup,
 	logger *logs.Logger,
 ) {
-	resultChan := monitorRunnerHealth(ctx, runnerServerURI, wg, logger)
+	resultChan := monitorRunnerHealth(ctx, runnerServerURI, runnerName, slot, wg, logger)
 
 	go func() {
 		result := <-resultChan
@@ -132,7 +166,8 @@ func ManageRunnerHealth(
 				panic(fmt.Errorf("failed to terminate unhealthy runner process: %v", err))
 			}
 		case StatusMonitoringCancelled:
-			// On cancellation via context, CommandContext will terminate the process, so no action.
+			// On cancellation via context, the runner has exited or is being stopped
+			// by the launcher on shutdown, so no action.
 		}
 	}()
 }
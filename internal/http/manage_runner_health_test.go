@@ -119,7 +119,7 @@ func TestMonitorRunnerHealth(t *testing.T) {
 
 			var wg sync.WaitGroup
 			logger := logs.NewLogger(logs.InfoLevel, "")
-			resultChan := monitorRunnerHealth(ctx, srv.URL, &wg, logger)
+			resultChan := monitorRunnerHealth(ctx, srv.URL, "javascript", 0, &wg, logger)
 
 			result := <-resultChan
 			assert.Equal(t, tt.expectedStatus, result.Status, "unexpected health status")
@@ -169,7 +169,7 @@ func TestManageRunnerHealth(t *testing.T) {
 			defer cancel()
 
 			logger := logs.NewLogger(logs.InfoLevel, "")
-			ManageRunnerHealth(ctx, cmd, srv.URL, &wg, logger)
+			ManageRunnerHealth(ctx, cmd, srv.URL, "javascript", 0, &wg, logger)
 
 			// For a healthy runner, we wait long enough for 3 health checks to pass.
 			// For an unhealthy runner, we wait long enough for 2 health checks to
@@ -207,7 +207,7 @@ func TestContextCancellation(t *testing.T) {
 	var wg sync.WaitGroup
 	logger := logs.NewLogger(logs.InfoLevel, "")
 
-	resultChan := monitorRunnerHealth(ctx, srv.URL, &wg, logger)
+	resultChan := monitorRunnerHealth(ctx, srv.URL, "javascript", 0, &wg, logger)
 
 	time.Sleep(20 * time.Millisecond) // short-lived until context is cancelled
 	cancel()
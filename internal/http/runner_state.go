@@ -0,0 +1,170 @@
+package http
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"sync"
+	"task-runner-launcher/internal/logs"
+)
+
+// RunnerState is the lifecycle state of a runner managed by the launcher.
+type RunnerState string
+
+const (
+	// StateWaitingForBroker indicates the launcher is waiting for the task broker to be ready.
+	StateWaitingForBroker RunnerState = "waiting-for-broker"
+
+	// StateHandshaking indicates the launcher is waiting for its task offer to be accepted.
+	StateHandshaking RunnerState = "handshaking"
+
+	// StateRunnerRunning indicates the runner is running and responding to health checks.
+	StateRunnerRunning RunnerState = "runner-running"
+
+	// StateRunnerUnhealthy indicates the runner is running but failing health checks.
+	StateRunnerUnhealthy RunnerState = "runner-unhealthy"
+
+	// StateFailed indicates the launcher has permanently stopped managing the runner.
+	StateFailed RunnerState = "failed"
+)
+
+// runnerStatus holds the state of the launcher goroutine of a runner, i.e.
+// waiting for the broker, handshaking or failed, and the state of the runner
+// process in each slot of its pool.
+type runnerStatus struct {
+	launcher RunnerState
+	slots    map[int]RunnerState
+}
+
+// state returns the state of the runner as a whole: failed if its launcher
+// goroutine failed, else unhealthy if any runner process is unhealthy, else
+// running if any runner process is running, else the launcher goroutine's state.
+func (s *runnerStatus) state() RunnerState {
+	if s.launcher == StateFailed {
+		return StateFailed
+	}
+
+	running := false
+	for _, state := range s.slots {
+		if state == StateRunnerUnhealthy {
+			return StateRunnerUnhealthy
+		}
+		running = running || state == StateRunnerRunning
+	}
+
+	if running {
+		return StateRunnerRunning
+	}
+
+	return s.launcher
+}
+
+var (
+	runnerStatesMu sync.RWMutex
+
+	// runnerStates holds the current status per runner name.
+	runnerStates = map[string]*runnerStatus{}
+)
+
+// statusOf returns the status of the given runner, to be called with the lock held.
+func statusOf(runnerName string) *runnerStatus {
+	status, ok := runnerStates[runnerName]
+	if !ok {
+		status = &runnerStatus{slots: map[int]RunnerState{}}
+		runnerStates[runnerName] = status
+	}
+
+	return status
+}
+
+// SetRunnerState sets the current state of the launcher goroutine of the given
+// runner, i.e. waiting for the broker, handshaking or failed.
+func SetRunnerState(runnerName string, state RunnerState) {
+	runnerStatesMu.Lock()
+	defer runnerStatesMu.Unlock()
+
+	statusOf(runnerName).launcher = state
+}
+
+// SetSlotState sets the current state of the runner process in the given slot
+// of the given runner's pool, i.e. running or unhealthy.
+func SetSlotState(runnerName string, slot int, state RunnerState) {
+	runnerStatesMu.Lock()
+	defer runnerStatesMu.Unlock()
+
+	statusOf(runnerName).slots[slot] = state
+}
+
+// ClearSlotState removes the state of the runner process in the given slot of
+// the given runner's pool, once the process has exited.
+func ClearSlotState(runnerName string, slot int) {
+	runnerStatesMu.Lock()
+	defer runnerStatesMu.Unlock()
+
+	delete(statusOf(runnerName).slots, slot)
+}
+
+type runnerStateResponse struct {
+	State RunnerState         `json:"state"`
+	Slots map[int]RunnerState `json:"slots,omitempty"` // by slot, for running runner processes
+}
+
+// getRunnerStates returns a snapshot of the current state per runner.
+func getRunnerStates() map[string]runnerStateResponse {
+	runnerStatesMu.RLock()
+	defer runnerStatesMu.RUnlock()
+
+	states := make(map[string]runnerStateResponse, len(runnerStates))
+	for runnerName, status := range runnerStates {
+		var slots map[int]RunnerState
+		if len(status.slots) > 0 {
+			slots = maps.Clone(status.slots)
+		}
+		states[runnerName] = runnerStateResponse{State: status.state(), Slots: slots}
+	}
+
+	return states
+}
+
+type readinessResponse struct {
+	Status  string                         `json:"status"`
+	Runners map[string]runnerStateResponse `json:"runners"`
+}
+
+// handleReadinessCheck reports the state of every runner, responding with
+// 503 if any runner has permanently failed.
+func handleReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	res := readinessResponse{
+		Status:  "ok",
+		Runners: map[string]runnerStateResponse{},
+	}
+
+	for runnerName, state := range getRunnerStates() {
+		res.Runners[runnerName] = state
+		if state.State == StateFailed {
+			res.Status = "failed"
+		}
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		logs.Errorf("Failed to encode readiness check response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if res.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		logs.Errorf("Failed to write readiness check response: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetRunnerStates(t *testing.T) {
+	t.Helper()
+
+	runnerStatesMu.Lock()
+	defer runnerStatesMu.Unlock()
+
+	runnerStates = map[string]*runnerStatus{}
+}
+
+func TestReadinessCheckHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		states         map[string]RunnerState
+		expectedStatus int
+		expectedBody   readinessResponse
+	}{
+		{
+			name:   "all runners in progress",
+			method: http.MethodGet,
+			states: map[string]RunnerState{
+				"javascript": StateHandshaking,
+				"python":     StateWaitingForBroker,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: readinessResponse{
+				Status: "ok",
+				Runners: map[string]runnerStateResponse{
+					"javascript": {State: StateHandshaking},
+					"python":     {State: StateWaitingForBroker},
+				},
+			},
+		},
+		{
+			name:   "unhealthy runner is not failure",
+			method: http.MethodGet,
+			states: map[string]RunnerState{
+				"javascript": StateRunnerUnhealthy,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: readinessResponse{
+				Status: "ok",
+				Runners: map[string]runnerStateResponse{
+					"javascript": {State: StateRunnerUnhealthy},
+				},
+			},
+		},
+		{
+			name:   "any failed runner returns 503",
+			method: http.MethodGet,
+			states: map[string]RunnerState{
+				"javascript": StateRunnerRunning,
+				"python":     StateFailed,
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: readinessResponse{
+				Status: "failed",
+				Runners: map[string]runnerStateResponse{
+					"javascript": {State: StateRunnerRunning},
+					"python":     {State: StateFailed},
+				},
+			},
+		},
+		{
+			name:           "POST request returns 405",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRunnerStates(t)
+			defer resetRunnerStates(t)
+
+			for runnerName, state := range tt.states {
+				SetRunnerState(runnerName, state)
+			}
+
+			req := httptest.NewRequest(tt.method, "/readyz", nil)
+			w := httptest.NewRecorder()
+
+			handleReadinessCheck(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "unexpected status code")
+
+			if tt.method == http.MethodGet {
+				var res readinessResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&res), "failed to decode response body")
+				assert.Equal(t, tt.expectedBody, res, "unexpected response body")
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "unexpected Content-Type header")
+			}
+		})
+	}
+}
+
+func TestRunnerStateAggregatesSlots(t *testing.T) {
+	resetRunnerStates(t)
+	defer resetRunnerStates(t)
+
+	state := func() runnerStateResponse {
+		return getRunnerStates()["javascript"]
+	}
+
+	SetRunnerState("javascript", StateHandshaking)
+	assert.Equal(t, runnerStateResponse{State: StateHandshaking}, state())
+
+	SetSlotState("javascript", 0, StateRunnerRunning)
+	SetSlotState("javascript", 1, StateRunnerRunning)
+	SetRunnerState("javascript", StateWaitingForBroker) // handshake for next slot does not hide running runners
+	assert.Equal(t, runnerStateResponse{
+		State: StateRunnerRunning,
+		Slots: map[int]RunnerState{0: StateRunnerRunning, 1: StateRunnerRunning},
+	}, state())
+
+	SetSlotState("javascript", 1, StateRunnerUnhealthy)
+	SetSlotState("javascript", 0, StateRunnerRunning) // healthy slot does not hide unhealthy slot
+	assert.Equal(t, StateRunnerUnhealthy, state().State)
+
+	ClearSlotState("javascript", 0)
+	ClearSlotState("javascript", 1)
+	assert.Equal(t, runnerStateResponse{State: StateWaitingForBroker}, state())
+
+	SetSlotState("javascript", 0, StateRunnerRunning)
+	SetRunnerState("javascript", StateFailed)
+	assert.Equal(t, StateFailed, state().State)
+}
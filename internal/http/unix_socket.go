@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// unixSocketBaseURL is the base URL of HTTP requests over a unix domain socket,
+// whose host is disregarded as the transport always dials the socket.
+const unixSocketBaseURL = "http://localhost"
+
+// unixTransports holds the transport per unix domain socket path, so that
+// connections are reused across requests to the same socket.
+var unixTransports sync.Map
+
+// UnixSocketPath returns the socket path of a `unix://` URI, e.g.
+// `/run/n8n/broker.sock` for `unix:///run/n8n/broker.sock`.
+func UnixSocketPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "unix" || u.Path == "" {
+		return "", false
+	}
+
+	return u.Path, true
+}
+
+// DialUnixSocket returns a dial func that connects to the unix domain socket
+// at the given path, whatever the address to dial.
+func DialUnixSocket(socketPath string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+}
+
+// unixTransport returns the transport for HTTP requests over the unix domain
+// socket at the given path. Requests over a socket never go through a proxy.
+func unixTransport(socketPath string) *http.Transport {
+	if transport, ok := unixTransports.Load(socketPath); ok {
+		return transport.(*http.Transport)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = DialUnixSocket(socketPath)
+
+	stored, _ := unixTransports.LoadOrStore(socketPath, transport)
+
+	return stored.(*http.Transport)
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnixSocketServer starts a server listening on a unix domain socket and
+// returns the socket path.
+func newUnixSocketServer(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	socketDir, err := os.MkdirTemp("", "http") // short path, as socket paths are limited in length
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(socketDir) })
+
+	socketPath := filepath.Join(socketDir, "server.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = listener
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return socketPath
+}
+
+func TestUnixSocketPath(t *testing.T) {
+	tests := []struct {
+		uri          string
+		expectedPath string
+		expectedOK   bool
+	}{
+		{uri: "unix:///run/n8n/broker.sock", expectedPath: "/run/n8n/broker.sock", expectedOK: true},
+		{uri: "unix://", expectedOK: false},
+		{uri: "http://127.0.0.1:5679", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			path, ok := UnixSocketPath(tt.uri)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedPath, path)
+		})
+	}
+}
+
+func TestBrokerClientOverUnixSocket(t *testing.T) {
+	socketPath := newUnixSocketServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/runners/auth" {
+			_, _ = w.Write([]byte(`{"data":{"token":"grant-token"}}`))
+		}
+	}))
+
+	client := NewBrokerClient(BrokerClientConfig{URI: "unix://" + socketPath})
+
+	statusCode, err := client.sendHealthRequest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+
+	token, err := client.sendGrantTokenRequest(context.Background(), "auth-token")
+	require.NoError(t, err)
+	assert.Equal(t, "grant-token", token)
+}
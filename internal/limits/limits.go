@@ -0,0 +1,119 @@
+package limits
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ExceededMemory is reported when a runner was killed by the OOM killer on
+	// reaching `memory-max`.
+	ExceededMemory = "memory-max"
+
+	// ExceededPids is reported when a runner failed to create a process or
+	// thread on reaching `pids-max`.
+	ExceededPids = "pids-max"
+)
+
+// ExecCommand is the hidden launcher command that sets `nofile-max` on itself
+// and then execs the runner command in the same process, so that the limit
+// applies before the runner runs: `<launcher> __exec-with-rlimits <nofile-max>
+// <path> <args>...`, where `args` include the runner's `argv[0]`.
+const ExecCommand = "__exec-with-rlimits"
+
+// Spec holds the resource limits for a runner process.
+type Spec struct {
+	// Max memory of the runner, in bytes or with a `K`, `M` or `G` suffix, e.g. "512M".
+	MemoryMax Bytes `json:"memory-max,omitempty" yaml:"memory-max,omitempty" toml:"memory-max,omitempty"`
+
+	// Max CPU time of the runner, in number of CPUs, e.g. 0.5 for half a CPU.
+	CPUMax float64 `json:"cpu-max,omitempty" yaml:"cpu-max,omitempty" toml:"cpu-max,omitempty"`
+
+	// Max number of processes and threads of the runner.
+	PidsMax int `json:"pids-max,omitempty" yaml:"pids-max,omitempty" toml:"pids-max,omitempty"`
+
+	// Max number of open files of the runner.
+	NofileMax int `json:"nofile-max,omitempty" yaml:"nofile-max,omitempty" toml:"nofile-max,omitempty"`
+}
+
+// MinCPUMax is the smallest CPU quota accepted by the kernel, i.e. 1ms per 100ms period.
+const MinCPUMax = 0.01
+
+// isEmpty returns whether no limit is set.
+func (s *Spec) isEmpty() bool {
+	return s == nil || *s == Spec{}
+}
+
+// Bytes is a size in bytes, unmarshaled from a number or from a string with
+// an optional binary `K`, `M` or `G` suffix.
+type Bytes uint64
+
+// BytesPattern matches the sizes accepted by ParseBytes.
+const BytesPattern = `^[0-9]+[KMGkmg]?$`
+
+var bytesRegex = regexp.MustCompile(BytesPattern)
+
+var byteSuffixes = map[string]uint64{
+	"":  1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+}
+
+// ParseBytes parses a size like "512M" into bytes.
+func ParseBytes(s string) (Bytes, error) {
+	if !bytesRegex.MatchString(s) {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 536870912, 512M or 1G", s)
+	}
+
+	digits := strings.TrimRight(s, "KMGkmg")
+	multiplier := byteSuffixes[strings.ToUpper(s[len(digits):])]
+
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 536870912, 512M or 1G", s)
+	}
+
+	if n > (1<<64-1)/multiplier {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return Bytes(n * multiplier), nil
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var n uint64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*b = Bytes(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid size %s, expected e.g. 536870912, \"512M\" or \"1G\"", data)
+	}
+
+	parsed, err := ParseBytes(s)
+	if err != nil {
+		return err
+	}
+
+	*b = parsed
+
+	return nil
+}
+
+// UnmarshalText parses a size given as a string in YAML or TOML config files.
+func (b *Bytes) UnmarshalText(text []byte) error {
+	parsed, err := ParseBytes(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = parsed
+
+	return nil
+}
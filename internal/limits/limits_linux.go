@@ -0,0 +1,354 @@
+package limits
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"task-runner-launcher/internal/logs"
+)
+
+const (
+	// cgroupRoot is where the cgroup v2 hierarchy is mounted.
+	cgroupRoot = "/sys/fs/cgroup"
+
+	// procSelfCgroup lists the cgroups of the launcher process.
+	procSelfCgroup = "/proc/self/cgroup"
+
+	// cpuPeriod is the period in microseconds that `cpu.max` quotas refer to.
+	cpuPeriod = 100000
+)
+
+// requiredControllers are the cgroup v2 controllers needed to enforce limits.
+var requiredControllers = []string{"cpu", "memory", "pids"}
+
+// defaultCgroups returns the cgroup manager, or an error if the launcher has no
+// delegated cgroup v2 sub-tree, in which case limits fall back to rlimits.
+var defaultCgroups = sync.OnceValues(func() (*cgroupManager, error) {
+	m, err := setupCgroups(cgroupRoot, procSelfCgroup)
+	if err != nil {
+		logs.Debugf("cgroup v2 is unavailable: %v", err)
+		return nil, err
+	}
+
+	logs.Debugf("Placing runners into cgroups under %s", m.base)
+
+	return m, nil
+})
+
+// cgroupManager creates a cgroup per runner process under the launcher's cgroup.
+type cgroupManager struct {
+	base string
+}
+
+// setupCgroups enables the required controllers for child cgroups of the
+// launcher's cgroup. As cgroup v2 allows controllers to be enabled only for
+// cgroups without processes of their own, the launcher moves itself into a
+// `launcher` leaf cgroup if needed.
+func setupCgroups(root, selfCgroupPath string) (*cgroupManager, error) {
+	data, err := os.ReadFile(selfCgroupPath) // #nosec G304 -- fixed path
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", selfCgroupPath, err)
+	}
+
+	var selfPath string
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			selfPath = path
+			break
+		}
+	}
+	if selfPath == "" {
+		return nil, errors.New("launcher is not in a cgroup v2 hierarchy")
+	}
+
+	base := filepath.Join(root, selfPath)
+
+	controllers, err := os.ReadFile(filepath.Join(base, "cgroup.controllers")) // #nosec G304 -- path from kernel
+	if err != nil {
+		return nil, fmt.Errorf("failed to read controllers of cgroup %s: %w", base, err)
+	}
+	available := strings.Fields(string(controllers))
+	for _, controller := range requiredControllers {
+		if !slices.Contains(available, controller) {
+			return nil, fmt.Errorf("controller %q is not delegated to cgroup %s", controller, base)
+		}
+	}
+
+	enable := "+" + strings.Join(requiredControllers, " +")
+	subtreeControl := filepath.Join(base, "cgroup.subtree_control")
+
+	err = os.WriteFile(subtreeControl, []byte(enable), 0)
+	if errors.Is(err, syscall.EBUSY) {
+		// cgroup still has processes, i.e. the launcher itself
+		leaf := filepath.Join(base, "launcher")
+		if err := os.Mkdir(leaf, 0o755); err != nil && !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create cgroup %s: %w", leaf, err)
+		}
+		pid := []byte(strconv.Itoa(os.Getpid()))
+		if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), pid, 0); err != nil {
+			return nil, fmt.Errorf("failed to move launcher into cgroup %s: %w", leaf, err)
+		}
+		err = os.WriteFile(subtreeControl, []byte(enable), 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable controllers for cgroup %s: %w", base, err)
+	}
+
+	return &cgroupManager{base: base}, nil
+}
+
+// create creates a cgroup with the given name and limits, replacing any
+// cgroup left over from a previous runner with the same name.
+func (m *cgroupManager) create(name string, spec *Spec) (string, error) {
+	path := filepath.Join(m.base, name)
+
+	_ = os.Remove(path) // only succeeds if left over and empty
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cgroup %s: %w", path, err)
+	}
+
+	settings := map[string]string{}
+	if spec.MemoryMax > 0 {
+		settings["memory.max"] = strconv.FormatUint(uint64(spec.MemoryMax), 10)
+		settings["memory.swap.max"] = "0" // else memory over the limit is swapped out instead of OOM-killed
+	}
+	if spec.CPUMax > 0 {
+		settings["cpu.max"] = fmt.Sprintf("%d %d", int(spec.CPUMax*cpuPeriod), cpuPeriod)
+	}
+	if spec.PidsMax > 0 {
+		settings["pids.max"] = strconv.Itoa(spec.PidsMax)
+	}
+
+	for file, value := range settings {
+		err := os.WriteFile(filepath.Join(path, file), []byte(value), 0)
+		if err != nil && !(file == "memory.swap.max" && os.IsNotExist(err)) { // no swap accounting
+			_ = os.Remove(path)
+			return "", fmt.Errorf("failed to set %s for cgroup %s: %w", file, path, err)
+		}
+	}
+
+	return path, nil
+}
+
+// exceeded returns which limit, if any, the processes in the cgroup exceeded.
+func exceeded(path string) string {
+	if readEvent(filepath.Join(path, "memory.events"), "oom_kill") > 0 {
+		return ExceededMemory
+	}
+
+	if readEvent(filepath.Join(path, "pids.events"), "max") > 0 {
+		return ExceededPids
+	}
+
+	return ""
+}
+
+// readEvent returns the counter for the given key in a cgroup events file, or
+// 0 if the file or key is missing.
+func readEvent(eventsPath, key string) int {
+	f, err := os.Open(eventsPath) // #nosec G304 -- path from cgroup created by launcher
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			n, _ := strconv.Atoi(fields[1])
+			return n
+		}
+	}
+
+	return 0
+}
+
+// Runner enforces resource limits on a runner process. Its methods are no-ops
+// on a nil Runner, i.e. for a runner without limits.
+type Runner struct {
+	spec   *Spec
+	logger *logs.Logger
+
+	cgroupPath string   // empty if falling back to rlimits
+	cgroupDir  *os.File // passed to the child to start it in its cgroup
+}
+
+// Apply prepares the command to start the runner process with the given
+// limits, in a cgroup named after the runner if cgroup v2 is delegated to the
+// launcher. [Runner.Started] must be called after the command is started, and
+// [Runner.Exited] after the process exits or fails to start.
+func Apply(cmd *exec.Cmd, name string, spec *Spec, logger *logs.Logger) (*Runner, error) {
+	if spec.isEmpty() {
+		return nil, nil
+	}
+
+	r := &Runner{spec: spec, logger: logger}
+
+	if spec.NofileMax > 0 {
+		if err := execWithRlimits(cmd, spec.NofileMax); err != nil {
+			return nil, err
+		}
+	}
+
+	cgroups, err := defaultCgroups()
+	if err != nil {
+		if unenforceable := cgroupLimits(spec); len(unenforceable) > 0 {
+			return nil, fmt.Errorf("cannot enforce %s without cgroup v2: %w", strings.Join(unenforceable, ", "), err)
+		}
+		return r, nil
+	}
+
+	r.cgroupPath, err = cgroups.create(name, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cgroupDir, err = os.Open(r.cgroupPath)
+	if err != nil {
+		_ = os.Remove(r.cgroupPath)
+		return nil, fmt.Errorf("failed to open cgroup %s: %w", r.cgroupPath, err)
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(r.cgroupDir.Fd())
+
+	logger.Debugf("Starting runner in cgroup %s", r.cgroupPath)
+
+	return r, nil
+}
+
+// Enforceable returns an error if any limit in the spec cannot be enforced, i.e.
+// `memory-max`, `cpu-max` or `pids-max` without a delegated cgroup v2 sub-tree.
+// These have no rlimit fallback, as no rlimit means the same: `RLIMIT_AS` also
+// counts address space reserved but never used, e.g. by V8, `RLIMIT_CPU` limits
+// total CPU time instead of CPU share, and `RLIMIT_NPROC` counts all processes
+// of the user instead of those of the runner.
+func Enforceable(spec *Spec) error {
+	unenforceable := cgroupLimits(spec)
+	if len(unenforceable) == 0 {
+		return nil
+	}
+
+	if _, err := defaultCgroups(); err != nil {
+		return fmt.Errorf("cannot enforce %s without cgroup v2: %w", strings.Join(unenforceable, ", "), err)
+	}
+
+	return nil
+}
+
+// cgroupLimits returns the names of the limits set in the spec that only a
+// cgroup can enforce.
+func cgroupLimits(spec *Spec) []string {
+	if spec == nil {
+		return nil
+	}
+
+	var names []string
+	if spec.MemoryMax > 0 {
+		names = append(names, "memory-max")
+	}
+	if spec.CPUMax > 0 {
+		names = append(names, "cpu-max")
+	}
+	if spec.PidsMax > 0 {
+		names = append(names, "pids-max")
+	}
+
+	return names
+}
+
+// execWithRlimits makes the command start the launcher's [ExecCommand], which
+// sets `nofile-max` before exec'ing the runner command in the same process.
+func execWithRlimits(cmd *exec.Cmd, nofileMax int) error {
+	if cmd.Err != nil {
+		return nil // reported by cmd.Start
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find launcher executable to set nofile-max: %w", err)
+	}
+
+	cmd.Args = append([]string{self, ExecCommand, strconv.Itoa(nofileMax), cmd.Path}, cmd.Args...)
+	cmd.Path = self
+
+	return nil
+}
+
+// Exec sets `nofile-max` and execs the runner command, given the args of
+// [ExecCommand]. Returns only on failure.
+func Exec(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: %s <nofile-max> <path> <args>...", ExecCommand)
+	}
+
+	nofileMax, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid nofile-max %q: %w", args[0], err)
+	}
+
+	// syscall.Setrlimit, unlike unix.Setrlimit, keeps the Go runtime from
+	// restoring the original limit on exec
+	rlimit := syscall.Rlimit{Cur: nofileMax, Max: nofileMax}
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return fmt.Errorf("failed to set nofile-max: %w", err)
+	}
+
+	// #nosec G204 -- runner command is controlled by system administrator via config file
+	if err := syscall.Exec(args[1], args[2:], os.Environ()); err != nil {
+		return fmt.Errorf("failed to exec runner command %s: %w", args[1], err)
+	}
+
+	return nil
+}
+
+// Started releases what the started runner process no longer needs.
+func (r *Runner) Started() {
+	if r == nil {
+		return
+	}
+
+	if r.cgroupDir != nil {
+		r.cgroupDir.Close()
+		r.cgroupDir = nil
+	}
+}
+
+// Exited returns which limit, if any, the runner exceeded, and removes the
+// runner's cgroup. A runner exceeding a limit is only detected with cgroups.
+func (r *Runner) Exited() string {
+	if r == nil {
+		return ""
+	}
+
+	if r.cgroupDir != nil {
+		r.cgroupDir.Close()
+		r.cgroupDir = nil
+	}
+
+	if r.cgroupPath == "" {
+		return ""
+	}
+
+	limit := exceeded(r.cgroupPath)
+
+	// kill any processes left behind by the runner so that the cgroup can be removed
+	_ = os.WriteFile(filepath.Join(r.cgroupPath, "cgroup.kill"), []byte("1"), 0)
+	if err := os.Remove(r.cgroupPath); err != nil {
+		r.logger.Debugf("Failed to remove cgroup %s: %v", r.cgroupPath, err)
+	}
+
+	return limit
+}
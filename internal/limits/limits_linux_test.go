@@ -0,0 +1,153 @@
+package limits
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the test binary as the launcher's ExecCommand when started as
+// such by execWithRlimits.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == ExecCommand {
+		err := Exec(os.Args[2:]) // returns only on failure
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	os.Exit(m.Run())
+}
+
+// fakeCgroupTree creates a cgroup v2 tree under a temp dir, with the launcher
+// in cgroup `/launcher.service`, and returns the root and the self cgroup file.
+func fakeCgroupTree(t *testing.T, controllers string) (string, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	base := filepath.Join(root, "launcher.service")
+	require.NoError(t, os.Mkdir(base, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "cgroup.controllers"), []byte(controllers), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "cgroup.subtree_control"), nil, 0o600))
+
+	selfCgroup := filepath.Join(root, "self-cgroup")
+	require.NoError(t, os.WriteFile(selfCgroup, []byte("0::/launcher.service\n"), 0o600))
+
+	return root, selfCgroup
+}
+
+func TestSetupCgroups(t *testing.T) {
+	t.Run("enables controllers for child cgroups", func(t *testing.T) {
+		root, selfCgroup := fakeCgroupTree(t, "cpuset cpu io memory pids")
+
+		m, err := setupCgroups(root, selfCgroup)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "launcher.service"), m.base)
+
+		subtreeControl, err := os.ReadFile(filepath.Join(m.base, "cgroup.subtree_control"))
+		require.NoError(t, err)
+		assert.Equal(t, "+cpu +memory +pids", string(subtreeControl))
+	})
+
+	t.Run("fails if controller is not delegated", func(t *testing.T) {
+		root, selfCgroup := fakeCgroupTree(t, "cpu pids")
+
+		_, err := setupCgroups(root, selfCgroup)
+		assert.ErrorContains(t, err, `controller "memory" is not delegated`)
+	})
+
+	t.Run("fails without cgroup v2", func(t *testing.T) {
+		root, selfCgroup := fakeCgroupTree(t, "cpu memory pids")
+		require.NoError(t, os.WriteFile(selfCgroup, []byte("4:memory:/\n1:cpu:/\n"), 0o600))
+
+		_, err := setupCgroups(root, selfCgroup)
+		assert.ErrorContains(t, err, "not in a cgroup v2 hierarchy")
+	})
+}
+
+func TestCgroupCreate(t *testing.T) {
+	root, selfCgroup := fakeCgroupTree(t, "cpu memory pids")
+	m, err := setupCgroups(root, selfCgroup)
+	require.NoError(t, err)
+
+	path, err := m.create("runner-javascript-0", &Spec{MemoryMax: 256 << 20, CPUMax: 0.5, PidsMax: 64})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"memory.max":      "268435456",
+		"memory.swap.max": "0",
+		"cpu.max":         "50000 100000",
+		"pids.max":        "64",
+	}
+	for file, value := range expected {
+		data, err := os.ReadFile(filepath.Join(path, file))
+		require.NoError(t, err, file)
+		assert.Equal(t, value, string(data), file)
+	}
+}
+
+func TestExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   map[string]string
+		expected string
+	}{
+		{
+			name:     "no limit reached",
+			events:   map[string]string{"memory.events": "low 0\nhigh 0\nmax 3\noom 0\noom_kill 0\n", "pids.events": "max 0\n"},
+			expected: "",
+		},
+		{
+			name:     "oom killed",
+			events:   map[string]string{"memory.events": "low 0\nhigh 0\nmax 12\noom 1\noom_kill 1\n", "pids.events": "max 0\n"},
+			expected: ExceededMemory,
+		},
+		{
+			name:     "pids limit reached",
+			events:   map[string]string{"memory.events": "oom_kill 0\n", "pids.events": "max 2\n"},
+			expected: ExceededPids,
+		},
+		{
+			name:     "missing events files",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir()
+			for file, content := range tt.events {
+				require.NoError(t, os.WriteFile(filepath.Join(path, file), []byte(content), 0o600))
+			}
+
+			assert.Equal(t, tt.expected, exceeded(path))
+		})
+	}
+}
+
+func TestExecWithRlimits(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "ulimit -Sn; ulimit -Hn")
+	require.NoError(t, execWithRlimits(cmd, 64))
+
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "64\n64\n", string(output))
+}
+
+func TestNilRunner(t *testing.T) {
+	r, err := Apply(exec.Command("true"), "runner-javascript-0", nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+	r.Started()
+	assert.Equal(t, "", r.Exited())
+}
+
+func TestCgroupLimits(t *testing.T) {
+	assert.Empty(t, cgroupLimits(nil))
+	assert.Empty(t, cgroupLimits(&Spec{NofileMax: 1024}), "nofile-max is enforced as an rlimit")
+	assert.Equal(t, []string{"memory-max", "cpu-max", "pids-max"}, cgroupLimits(&Spec{MemoryMax: 1 << 30, CPUMax: 0.5, PidsMax: 64, NofileMax: 1024}))
+}
@@ -0,0 +1,42 @@
+//go:build !linux
+
+package limits
+
+import (
+	"errors"
+	"os/exec"
+	"task-runner-launcher/internal/logs"
+)
+
+// Runner enforces resource limits on a runner process. Resource limits are
+// only supported on Linux.
+type Runner struct{}
+
+// Apply returns an error if any limit is set, as resource limits are only
+// supported on Linux.
+func Apply(_ *exec.Cmd, _ string, spec *Spec, _ *logs.Logger) (*Runner, error) {
+	if spec.isEmpty() {
+		return nil, nil
+	}
+
+	return nil, errors.New("resource limits are only supported on Linux")
+}
+
+// Enforceable returns an error if any limit is set, as resource limits are only
+// supported on Linux.
+func Enforceable(spec *Spec) error {
+	if spec.isEmpty() {
+		return nil
+	}
+
+	return errors.New("resource limits are only supported on Linux")
+}
+
+func (r *Runner) Started() {}
+
+// Exec returns an error, as resource limits are only supported on Linux.
+func Exec(_ []string) error {
+	return errors.New("resource limits are only supported on Linux")
+}
+
+func (r *Runner) Exited() string { return "" }
@@ -0,0 +1,54 @@
+package limits
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Bytes
+		wantErr  bool
+	}{
+		{input: "1024", expected: 1024},
+		{input: "4K", expected: 4 << 10},
+		{input: "512M", expected: 512 << 20},
+		{input: "2g", expected: 2 << 30},
+		{input: "", wantErr: true},
+		{input: "M", wantErr: true},
+		{input: "512X", wantErr: true},
+		{input: "-1M", wantErr: true},
+		{input: " 512M", wantErr: true},
+		{input: "99999999999G", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseBytes(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSpecUnmarshalJSON(t *testing.T) {
+	var spec Spec
+	err := json.Unmarshal([]byte(`{"memory-max": "256M", "cpu-max": 0.5, "pids-max": 64, "nofile-max": 1024}`), &spec)
+	require.NoError(t, err)
+	assert.Equal(t, Spec{MemoryMax: 256 << 20, CPUMax: 0.5, PidsMax: 64, NofileMax: 1024}, spec)
+
+	err = json.Unmarshal([]byte(`{"memory-max": 1048576}`), &spec)
+	require.NoError(t, err)
+	assert.Equal(t, Bytes(1<<20), spec.MemoryMax)
+
+	err = json.Unmarshal([]byte(`{"memory-max": true}`), &spec)
+	assert.ErrorContains(t, err, "invalid size")
+}
@@ -1,10 +1,14 @@
 package logs
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type Level int
@@ -34,6 +38,35 @@ func (l Level) String() string {
 	return levelNames[l]
 }
 
+type Format int
+
+const (
+	// TextFormat is human-readable, colorized text output.
+	TextFormat Format = iota
+
+	// JSONFormat is one JSON object per line, for parsing by log pipelines.
+	JSONFormat
+)
+
+var formatMap = map[string]Format{
+	"text": TextFormat,
+	"json": JSONFormat,
+}
+
+// logFormat is the output format for all launcher and runner logs.
+var logFormat = TextFormat
+
+// SetFormat sets the output format for all launcher and runner logs.
+func SetFormat(format Format) {
+	logFormat = format
+}
+
+// IsValidFormat reports whether the given format name is supported.
+func IsValidFormat(format string) bool {
+	_, ok := formatMap[strings.ToLower(format)]
+	return ok
+}
+
 var (
 	ColorReset  = "\033[0m"
 	ColorRed    = "\033[31m"
@@ -57,22 +90,50 @@ var abbreviations = map[string]string{
 	"python":     "py",
 }
 
-// GetLauncherPrefix returns the formatted prefix for launcher logs
-func GetLauncherPrefix(runnerType string) string {
-	if abbr, ok := abbreviations[runnerType]; ok {
+// GetLauncherPrefix returns the formatted prefix for launcher logs, given the
+// runner name, which defaults to the runner type.
+func GetLauncherPrefix(runnerName string) string {
+	if abbr, ok := abbreviations[runnerName]; ok {
 		return fmt.Sprintf("[launcher:%s] ", abbr)
 	}
 
-	return fmt.Sprintf("[launcher:%s] ", runnerType)
+	return fmt.Sprintf("[launcher:%s] ", runnerName)
 }
 
-// GetRunnerPrefix returns the formatted prefix for runner logs
-func GetRunnerPrefix(runnerType string) string {
-	if abbr, ok := abbreviations[runnerType]; ok {
+// GetRunnerPrefix returns the formatted prefix for runner logs, given the
+// runner name, which defaults to the runner type.
+func GetRunnerPrefix(runnerName string) string {
+	if abbr, ok := abbreviations[runnerName]; ok {
 		return fmt.Sprintf("[runner:%s] ", abbr)
 	}
 
-	return fmt.Sprintf("[runner:%s] ", runnerType)
+	return fmt.Sprintf("[runner:%s] ", runnerName)
+}
+
+const (
+	componentLauncher = "launcher"
+	componentRunner   = "runner"
+)
+
+// jsonEntry is a single log line in JSON format.
+type jsonEntry struct {
+	Timestamp  string `json:"timestamp"`
+	Level      string `json:"level"`
+	Component  string `json:"component"`
+	RunnerType string `json:"runnerType,omitempty"`
+	RunnerName string `json:"runnerName,omitempty"`
+	LauncherID string `json:"launcherId,omitempty"`
+	Message    string `json:"message"`
+}
+
+// writeJSON writes a log line in JSON format, bypassing any `log.Logger` flags.
+func writeJSON(w io.Writer, entry jsonEntry) {
+	entry.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(append(line, '\n'))
 }
 
 // ------------------------
@@ -80,12 +141,15 @@ func GetRunnerPrefix(runnerType string) string {
 // ------------------------
 
 type Logger struct {
-	debug  *log.Logger
-	info   *log.Logger
-	warn   *log.Logger
-	err    *log.Logger
-	level  Level
-	prefix string
+	debug      *log.Logger
+	info       *log.Logger
+	warn       *log.Logger
+	err        *log.Logger
+	level      Level
+	prefix     string
+	runnerType string
+	runnerName string
+	launcherID atomic.Pointer[string]
 }
 
 func NewLogger(level Level, prefix string) *Logger {
@@ -99,52 +163,118 @@ func NewLogger(level Level, prefix string) *Logger {
 	}
 }
 
+// NewLauncherLogger creates a logger for the launcher goroutine managing the
+// runner with the given type and name.
+func NewLauncherLogger(level Level, runnerType, runnerName string) *Logger {
+	l := NewLogger(level, GetLauncherPrefix(runnerName))
+	l.runnerType, l.runnerName = runnerType, runnerName
+
+	return l
+}
+
+// SetLauncherID sets the launcher ID to include in JSON logs.
+func (l *Logger) SetLauncherID(launcherID string) {
+	l.launcherID.Store(&launcherID)
+}
+
+// LauncherID returns the launcher ID last set on the logger, if any.
+func (l *Logger) LauncherID() string {
+	if id := l.launcherID.Load(); id != nil {
+		return *id
+	}
+
+	return ""
+}
+
+func (l *Logger) writeJSON(out *log.Logger, level Level, msg string) {
+	writeJSON(out.Writer(), jsonEntry{
+		Level:      strings.ToLower(level.String()),
+		Component:  componentLauncher,
+		RunnerType: l.runnerType,
+		RunnerName: l.runnerName,
+		LauncherID: l.LauncherID(),
+		Message:    msg,
+	})
+}
+
 var logger = NewLogger(InfoLevel, "")
 
 func (l *Logger) Debug(msg string) {
 	if l.level <= DebugLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.debug, DebugLevel, msg)
+			return
+		}
 		l.debug.Printf("%sDEBUG %s%s%s", ColorCyan, l.prefix, msg, ColorReset)
 	}
 }
 
 func (l *Logger) Debugf(msg string, xs ...any) {
 	if l.level <= DebugLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.debug, DebugLevel, fmt.Sprintf(msg, xs...))
+			return
+		}
 		l.debug.Printf(fmt.Sprintf("%sDEBUG %s%s%s", ColorCyan, l.prefix, msg, ColorReset), xs...)
 	}
 }
 
 func (l *Logger) Info(msg string) {
 	if l.level <= InfoLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.info, InfoLevel, msg)
+			return
+		}
 		l.info.Printf("%sINFO  %s%s%s", ColorBlue, l.prefix, msg, ColorReset)
 	}
 }
 
 func (l *Logger) Infof(msg string, xs ...any) {
 	if l.level <= InfoLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.info, InfoLevel, fmt.Sprintf(msg, xs...))
+			return
+		}
 		l.info.Printf(fmt.Sprintf("%sINFO  %s%s%s", ColorBlue, l.prefix, msg, ColorReset), xs...)
 	}
 }
 
 func (l *Logger) Warn(msg string) {
 	if l.level <= WarnLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.warn, WarnLevel, msg)
+			return
+		}
 		l.warn.Printf("%sWARN  %s%s%s", ColorYellow, l.prefix, msg, ColorReset)
 	}
 }
 
 func (l *Logger) Warnf(msg string, xs ...any) {
 	if l.level <= WarnLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.warn, WarnLevel, fmt.Sprintf(msg, xs...))
+			return
+		}
 		l.warn.Printf(fmt.Sprintf("%sWARN %s%s%s", ColorYellow, l.prefix, msg, ColorReset), xs...)
 	}
 }
 
 func (l *Logger) Error(msg string) {
 	if l.level <= ErrorLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.warn, ErrorLevel, msg)
+			return
+		}
 		l.warn.Printf("%sERROR %s%s%s", ColorRed, l.prefix, msg, ColorReset)
 	}
 }
 
 func (l *Logger) Errorf(msg string, xs ...any) {
 	if l.level <= ErrorLevel {
+		if logFormat == JSONFormat {
+			l.writeJSON(l.err, ErrorLevel, fmt.Sprintf(msg, xs...))
+			return
+		}
 		l.err.Printf(fmt.Sprintf("%sERROR %s%s%s", ColorRed, l.prefix, msg, ColorReset), xs...)
 	}
 }
@@ -153,6 +283,14 @@ func (l *Logger) Errorf(msg string, xs ...any) {
 //          API
 // ------------------------
 
+func ParseFormat(format string) Format {
+	if f, ok := formatMap[strings.ToLower(format)]; ok {
+		return f
+	}
+
+	return TextFormat
+}
+
 func ParseLevel(level string) Level {
 	if lvl, ok := levelMap[strings.ToLower(level)]; ok {
 		return lvl
@@ -2,11 +2,14 @@ package logs
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type logTest struct {
@@ -291,3 +294,44 @@ func TestGetRunnerPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONFormat(t *testing.T) {
+	SetFormat(JSONFormat)
+	defer SetFormat(TextFormat)
+
+	var buf bytes.Buffer
+	l := NewLauncherLogger(DebugLevel, "javascript", "js-node22")
+	l.debug = log.New(&buf, "", log.LstdFlags)
+	l.warn = log.New(&buf, "", log.LstdFlags)
+	l.SetLauncherID("test-launcher-id")
+
+	l.Debugf("test %s message", "debug")
+	l.Warn("test warn message")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "Expected one JSON object per line")
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry), "Log line should be valid JSON")
+	assert.Equal(t, "debug", entry["level"])
+	assert.Equal(t, "launcher", entry["component"])
+	assert.Equal(t, "javascript", entry["runnerType"])
+	assert.Equal(t, "js-node22", entry["runnerName"])
+	assert.Equal(t, "test-launcher-id", entry["launcherId"])
+	assert.Equal(t, "test debug message", entry["message"])
+	assert.NotEmpty(t, entry["timestamp"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry), "Log line should be valid JSON")
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "test warn message", entry["message"])
+}
+
+func TestParseFormat(t *testing.T) {
+	assert.Equal(t, JSONFormat, ParseFormat("json"))
+	assert.Equal(t, JSONFormat, ParseFormat("JSON"))
+	assert.Equal(t, TextFormat, ParseFormat("text"))
+	assert.Equal(t, TextFormat, ParseFormat("unknown"))
+
+	assert.True(t, IsValidFormat("json"))
+	assert.False(t, IsValidFormat("xml"))
+}
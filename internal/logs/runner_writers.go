@@ -10,11 +10,14 @@ import (
 
 // RunnerWriter wraps runner output with timestamps and prefixes.
 type RunnerWriter struct {
-	writer   *log.Logger
-	prefix   string
-	color    string
-	level    Level
-	minLevel Level
+	writer     *log.Logger
+	prefix     string
+	color      string
+	level      Level
+	minLevel   Level
+	runnerType string
+	runnerName string
+	launcherID string
 }
 
 // NewRunnerWriter creates a new wrapper for runner output.
@@ -41,6 +44,17 @@ func (w *RunnerWriter) Write(p []byte) (n int, err error) {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
+		if logFormat == JSONFormat {
+			writeJSON(w.writer.Writer(), jsonEntry{
+				Level:      strings.ToLower(w.level.String()),
+				Component:  componentRunner,
+				RunnerType: w.runnerType,
+				RunnerName: w.runnerName,
+				LauncherID: w.launcherID,
+				Message:    line,
+			})
+			continue
+		}
 		w.writer.Printf("%s%s %s%s%s", w.color, w.level, w.prefix, line, ColorReset)
 	}
 
@@ -51,10 +65,16 @@ func (w *RunnerWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-// GetRunnerWriters returns configured `stdout` and `stderr` writers with a custom prefix.
-func GetRunnerWriters(minLevel Level, prefix string) (stdout io.Writer, stderr io.Writer) {
-	stdout = NewRunnerWriter(os.Stdout, prefix, ColorCyan, DebugLevel, minLevel)
-	stderr = NewRunnerWriter(os.Stderr, prefix, ColorRed, ErrorLevel, minLevel)
+// GetRunnerWriters returns configured `stdout` and `stderr` writers for the
+// runner with the given type and name, launched by the launcher with the given ID.
+func GetRunnerWriters(minLevel Level, runnerType, runnerName, launcherID string) (stdout io.Writer, stderr io.Writer) {
+	prefix := GetRunnerPrefix(runnerName)
+
+	stdoutWriter := NewRunnerWriter(os.Stdout, prefix, ColorCyan, DebugLevel, minLevel)
+	stdoutWriter.runnerType, stdoutWriter.runnerName, stdoutWriter.launcherID = runnerType, runnerName, launcherID
+
+	stderrWriter := NewRunnerWriter(os.Stderr, prefix, ColorRed, ErrorLevel, minLevel)
+	stderrWriter.runnerType, stderrWriter.runnerName, stderrWriter.launcherID = runnerType, runnerName, launcherID
 
-	return stdout, stderr
+	return stdoutWriter, stderrWriter
 }
@@ -2,7 +2,9 @@ package logs
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,8 +117,7 @@ func TestRunnerWriter(t *testing.T) {
 }
 
 func TestGetRunnerWriters(t *testing.T) {
-	prefix := "[runner:js] "
-	stdout, stderr := GetRunnerWriters(DebugLevel, prefix)
+	stdout, stderr := GetRunnerWriters(DebugLevel, "javascript", "javascript", "test-launcher-id")
 
 	assert.NotNil(t, stdout, "GetRunnerWriters() stdout should not be nil")
 	assert.NotNil(t, stderr, "GetRunnerWriters() stderr should not be nil")
@@ -128,8 +129,8 @@ func TestGetRunnerWriters(t *testing.T) {
 }
 
 func TestGetRunnerWritersWithDifferentTypes(t *testing.T) {
-	GetRunnerWriters(DebugLevel, "[runner:js] ")
-	GetRunnerWriters(DebugLevel, "[runner:py] ")
+	GetRunnerWriters(DebugLevel, "javascript", "javascript", "")
+	GetRunnerWriters(DebugLevel, "python", "python", "")
 
 	var jsBuf, pyBuf bytes.Buffer
 	jsWriter := NewRunnerWriter(&jsBuf, "[runner:js] ", ColorCyan, DebugLevel, DebugLevel)
@@ -147,3 +148,27 @@ func TestGetRunnerWritersWithDifferentTypes(t *testing.T) {
 	assert.Contains(t, pyOutput, "[runner:py]", "Python runner should have correct prefix")
 	assert.NotEqual(t, jsOutput, pyOutput, "Different runner types should have different output")
 }
+
+func TestRunnerWriterJSONFormat(t *testing.T) {
+	SetFormat(JSONFormat)
+	defer SetFormat(TextFormat)
+
+	var buf bytes.Buffer
+	writer := NewRunnerWriter(&buf, "[runner:js] ", ColorRed, ErrorLevel, DebugLevel)
+	writer.runnerType, writer.launcherID = "javascript", "test-launcher-id"
+
+	_, err := writer.Write([]byte("line1\n\nline2"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "Expected one JSON object per non-empty line")
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry), "Log line should be valid JSON")
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "runner", entry["component"])
+	assert.Equal(t, "javascript", entry["runnerType"])
+	assert.Equal(t, "test-launcher-id", entry["launcherId"])
+	assert.Equal(t, "line2", entry["message"])
+	assert.NotContains(t, lines[1], ColorRed, "JSON output should not be colorized")
+}
@@ -0,0 +1,228 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ExitReasonIdle is the exit reason for a runner that shut down on idle timeout.
+	ExitReasonIdle = "idle"
+
+	// ExitReasonError is the exit reason for a runner that exited with an error.
+	ExitReasonError = "error"
+
+	// ExitReasonUnresponsive is the exit reason for a runner that was killed
+	// after failing too many health checks.
+	ExitReasonUnresponsive = "unresponsive"
+
+	// ExitReasonOOMKilled is the exit reason for a runner killed by the OOM
+	// killer on reaching its memory limit.
+	ExitReasonOOMKilled = "oom-killed"
+
+	// ExitReasonLimitExceeded is the exit reason for a runner that exited with an
+	// error after reaching a resource limit other than its memory limit.
+	ExitReasonLimitExceeded = "limit-exceeded"
+
+	// ExitReasonShutdown is the exit reason for a runner stopped on launcher shutdown.
+	ExitReasonShutdown = "shutdown"
+)
+
+// counterVec is a counter partitioned by label values.
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64 // key: label values joined by `\xff`
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+// Inc increments the counter for the given label values.
+func (c *counterVec) Inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[strings.Join(labelValues, "\xff")]++
+}
+
+// Value returns the counter for the given label values.
+func (c *counterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.values[strings.Join(labelValues, "\xff")]
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeFamily(w, c.name, c.help, "counter", c.labels, c.values)
+}
+
+var (
+	// HandshakesCompleted counts handshakes where the launcher's offer was accepted.
+	HandshakesCompleted = newCounterVec(
+		"n8n_launcher_handshakes_completed_total",
+		"Number of handshakes completed with the task broker.",
+		"runner_name",
+	)
+
+	// RunnerLaunches counts runner processes started.
+	RunnerLaunches = newCounterVec(
+		"n8n_launcher_runner_launches_total",
+		"Number of runner processes launched.",
+		"runner_name", "runner_type",
+	)
+
+	// RunnerExits counts runner processes exited, by exit reason.
+	RunnerExits = newCounterVec(
+		"n8n_launcher_runner_exits_total",
+		"Number of runner processes exited, by reason.",
+		"runner_name", "runner_type", "reason",
+	)
+
+	// HealthCheckFailures counts failed runner health checks.
+	HealthCheckFailures = newCounterVec(
+		"n8n_launcher_runner_health_check_failures_total",
+		"Number of failed runner health checks.",
+		"runner_name",
+	)
+
+	// GrantTokenFetchRetries counts retried grant token fetches.
+	GrantTokenFetchRetries = newCounterVec(
+		"n8n_launcher_grant_token_fetch_retries_total",
+		"Number of retried grant token fetches.",
+		"runner_name",
+	)
+
+	// BrokerReconnects counts reconnects after finding the task broker down.
+	BrokerReconnects = newCounterVec(
+		"n8n_launcher_broker_reconnects_total",
+		"Number of reconnects to the task broker after finding it down.",
+		"runner_name",
+	)
+
+	counters = []*counterVec{
+		HandshakesCompleted,
+		RunnerLaunches,
+		RunnerExits,
+		HealthCheckFailures,
+		GrantTokenFetchRetries,
+		BrokerReconnects,
+	}
+)
+
+var (
+	runnerStartTimesMu sync.Mutex
+
+	// runnerStartTimes holds the start time of the running runner per runner
+	// name, runner type and slot, keyed like counter values.
+	runnerStartTimes = map[string]time.Time{}
+
+	// now is overridable for testing.
+	now = time.Now
+)
+
+// RunnerStarted records that a runner with the given name and type was launched
+// in the given slot.
+func RunnerStarted(runnerName, runnerType string, slot int) {
+	RunnerLaunches.Inc(runnerName, runnerType)
+
+	runnerStartTimesMu.Lock()
+	defer runnerStartTimesMu.Unlock()
+
+	runnerStartTimes[runnerName+"\xff"+runnerType+"\xff"+strconv.Itoa(slot)] = now()
+}
+
+// RunnerExited records that a runner with the given name and type in the given
+// slot exited for the given reason.
+func RunnerExited(runnerName, runnerType string, slot int, reason string) {
+	RunnerExits.Inc(runnerName, runnerType, reason)
+
+	runnerStartTimesMu.Lock()
+	defer runnerStartTimesMu.Unlock()
+
+	runnerStartTimes[runnerName+"\xff"+runnerType+"\xff"+strconv.Itoa(slot)] = time.Time{}
+}
+
+func writeRunnerUptime(w io.Writer) {
+	runnerStartTimesMu.Lock()
+	defer runnerStartTimesMu.Unlock()
+
+	values := make(map[string]float64, len(runnerStartTimes))
+	for key, startTime := range runnerStartTimes {
+		if startTime.IsZero() {
+			values[key] = 0
+		} else {
+			values[key] = now().Sub(startTime).Seconds()
+		}
+	}
+
+	writeFamily(
+		w,
+		"n8n_launcher_runner_uptime_seconds",
+		"Uptime of the runner process running in a slot, 0 if none is running.",
+		"gauge",
+		[]string{"runner_name", "runner_type", "slot"},
+		values,
+	)
+}
+
+// labelValueEscaper escapes label values as the Prometheus text exposition
+// format expects, which unlike Go escapes only backslashes, quotes and newlines.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeFamily writes a metric family in the Prometheus text exposition format.
+func writeFamily(w io.Writer, name, help, metricType string, labels []string, values map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // ensure consistent order
+
+	for _, key := range keys {
+		labelValues := strings.Split(key, "\xff")
+		pairs := make([]string, len(labels))
+		for i, label := range labels {
+			value := ""
+			if i < len(labelValues) {
+				value = labelValues[i]
+			}
+			pairs[i] = fmt.Sprintf(`%s="%s"`, label, labelValueEscaper.Replace(value))
+		}
+		fmt.Fprintf(w, "%s{%s} %v\n", name, strings.Join(pairs, ","), values[key])
+	}
+}
+
+// Handler returns an HTTP handler exposing all metrics in the Prometheus text
+// exposition format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		for _, c := range counters {
+			c.write(w)
+		}
+		writeRunnerUptime(w)
+	})
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterVec(t *testing.T) {
+	c := newCounterVec("test_total", "Test counter.", "runner_name", "reason")
+
+	c.Inc("javascript", "idle")
+	c.Inc("javascript", "idle")
+	c.Inc("python", "error")
+
+	assert.Equal(t, float64(2), c.Value("javascript", "idle"))
+	assert.Equal(t, float64(1), c.Value("python", "error"))
+	assert.Equal(t, float64(0), c.Value("python", "idle"))
+}
+
+func TestRunnerUptime(t *testing.T) {
+	origNow := now
+	defer func() { now = origNow }()
+
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return startTime }
+
+	RunnerStarted("uptime-test", "javascript", 0)
+	now = func() time.Time { return startTime.Add(90 * time.Second) }
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `n8n_launcher_runner_uptime_seconds{runner_name="uptime-test",runner_type="javascript",slot="0"} 90`)
+
+	RunnerExited("uptime-test", "javascript", 0, ExitReasonIdle)
+
+	rec = httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `n8n_launcher_runner_uptime_seconds{runner_name="uptime-test",runner_type="javascript",slot="0"} 0`)
+	assert.Contains(t, rec.Body.String(), `n8n_launcher_runner_exits_total{runner_name="uptime-test",runner_type="javascript",reason="idle"} 1`)
+}
+
+func TestWriteFamilyEscapesLabelValues(t *testing.T) {
+	var buf strings.Builder
+	writeFamily(&buf, "test_total", "Test.", "counter", []string{"runner_name"}, map[string]float64{
+		"js\\\"\nü\t": 1,
+	})
+
+	assert.Contains(t, buf.String(), `test_total{runner_name="js\\\"\nü`+"\t"+`"} 1`)
+}
+
+func TestHandler(t *testing.T) {
+	HandshakesCompleted.Inc("handler-test")
+
+	tests := []struct {
+		name           string
+		method         string
+		expectedStatus int
+	}{
+		{
+			name:           "GET request returns metrics",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "POST request returns 405",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler().ServeHTTP(rec, httptest.NewRequest(tt.method, "/metrics", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, "unexpected status code")
+
+			if tt.expectedStatus == http.StatusOK {
+				body := rec.Body.String()
+				assert.Contains(t, body, "# TYPE n8n_launcher_handshakes_completed_total counter")
+				assert.Contains(t, body, `n8n_launcher_handshakes_completed_total{runner_name="handler-test"} 1`)
+				assert.Contains(t, body, "# TYPE n8n_launcher_runner_uptime_seconds gauge")
+				assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+			}
+		})
+	}
+}
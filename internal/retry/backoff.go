@@ -0,0 +1,121 @@
+package retry
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// StrategyConstant waits the same delay between all retries.
+	StrategyConstant = "constant"
+
+	// StrategyExponential doubles the delay on every retry, up to the max delay.
+	StrategyExponential = "exponential"
+
+	// StrategyDecorrelatedJitter picks a random delay between the base delay and
+	// three times the previous delay, up to the max delay, so that many clients
+	// retrying at once spread out over time instead of retrying in lockstep.
+	StrategyDecorrelatedJitter = "decorrelated-jitter"
+)
+
+// Backoff computes how long to wait between retries.
+type Backoff interface {
+	// Next returns the time to wait after the given failed attempt (starting at 1),
+	// given the time waited after the previous failed attempt (0 if none).
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// ConstantBackoff waits the same delay between all retries.
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+func (b ConstantBackoff) Next(_ int, _ time.Duration) time.Duration {
+	return b.Delay
+}
+
+// ExponentialBackoff waits `BaseDelay * 2^(attempt-1)`, capped at `MaxDelay`.
+type ExponentialBackoff struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (b ExponentialBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	delay := b.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= b.MaxDelay || delay <= 0 {
+			return b.MaxDelay
+		}
+	}
+
+	return min(delay, b.MaxDelay)
+}
+
+// DecorrelatedJitterBackoff waits a random delay between `BaseDelay` and three
+// times the previous delay, capped at `MaxDelay`.
+type DecorrelatedJitterBackoff struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (b DecorrelatedJitterBackoff) Next(_ int, prev time.Duration) time.Duration {
+	upper := max(prev*3, b.BaseDelay)
+	// #nosec G404 -- jitter does not require a cryptographically secure source
+	delay := b.BaseDelay + rand.N(upper-b.BaseDelay+1)
+
+	return min(delay, b.MaxDelay)
+}
+
+// NewBackoff returns the backoff for the given strategy name.
+func NewBackoff(strategy string, baseDelay, maxDelay time.Duration) (Backoff, error) {
+	if baseDelay <= 0 {
+		return nil, fmt.Errorf("base delay must be positive, got %v", baseDelay)
+	}
+
+	if maxDelay < baseDelay {
+		return nil, fmt.Errorf("max delay (%v) must not be less than base delay (%v)", maxDelay, baseDelay)
+	}
+
+	switch strategy {
+	case StrategyConstant:
+		return ConstantBackoff{Delay: baseDelay}, nil
+	case StrategyExponential:
+		return ExponentialBackoff{BaseDelay: baseDelay, MaxDelay: maxDelay}, nil
+	case StrategyDecorrelatedJitter:
+		return DecorrelatedJitterBackoff{BaseDelay: baseDelay, MaxDelay: maxDelay}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown backoff strategy %q, must be one of: %s, %s, %s",
+			strategy,
+			StrategyConstant,
+			StrategyExponential,
+			StrategyDecorrelatedJitter,
+		)
+	}
+}
+
+var (
+	backoffsMu sync.RWMutex
+
+	// backoffs holds the backoff to use per operation name, if any.
+	backoffs = map[string]Backoff{}
+)
+
+// SetBackoff sets the backoff to use for all retries of the given operation.
+func SetBackoff(operationName string, backoff Backoff) {
+	backoffsMu.Lock()
+	defer backoffsMu.Unlock()
+
+	backoffs[operationName] = backoff
+}
+
+// backoffFor returns the backoff set for the given operation, or nil if none is set.
+func backoffFor(operationName string) Backoff {
+	backoffsMu.RLock()
+	defer backoffsMu.RUnlock()
+
+	return backoffs[operationName]
+}
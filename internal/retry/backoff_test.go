@@ -0,0 +1,145 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	b := ConstantBackoff{Delay: 5 * time.Second}
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		assert.Equal(t, 5*time.Second, b.Next(attempt, 0))
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: time.Second},
+		{attempt: 2, expected: 2 * time.Second},
+		{attempt: 3, expected: 4 * time.Second},
+		{attempt: 4, expected: 8 * time.Second},
+		{attempt: 5, expected: 10 * time.Second},
+		{attempt: 100, expected: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, b.Next(tt.attempt, 0), "unexpected delay for attempt %d", tt.attempt)
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	b := DecorrelatedJitterBackoff{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	var prev time.Duration
+	for attempt := 1; attempt <= 100; attempt++ {
+		delay := b.Next(attempt, prev)
+		assert.GreaterOrEqual(t, delay, b.BaseDelay, "delay below base delay")
+		assert.LessOrEqual(t, delay, b.MaxDelay, "delay above max delay")
+		assert.LessOrEqual(t, delay, max(prev*3, b.BaseDelay), "delay above three times previous delay")
+		prev = delay
+	}
+}
+
+func TestNewBackoff(t *testing.T) {
+	tests := []struct {
+		name          string
+		strategy      string
+		baseDelay     time.Duration
+		maxDelay      time.Duration
+		expected      Backoff
+		expectedError string
+	}{
+		{
+			name:      "constant",
+			strategy:  StrategyConstant,
+			baseDelay: time.Second,
+			maxDelay:  time.Minute,
+			expected:  ConstantBackoff{Delay: time.Second},
+		},
+		{
+			name:      "exponential",
+			strategy:  StrategyExponential,
+			baseDelay: time.Second,
+			maxDelay:  time.Minute,
+			expected:  ExponentialBackoff{BaseDelay: time.Second, MaxDelay: time.Minute},
+		},
+		{
+			name:      "decorrelated jitter",
+			strategy:  StrategyDecorrelatedJitter,
+			baseDelay: time.Second,
+			maxDelay:  time.Minute,
+			expected:  DecorrelatedJitterBackoff{BaseDelay: time.Second, MaxDelay: time.Minute},
+		},
+		{
+			name:          "unknown strategy",
+			strategy:      "linear",
+			baseDelay:     time.Second,
+			maxDelay:      time.Minute,
+			expectedError: "unknown backoff strategy",
+		},
+		{
+			name:          "non-positive base delay",
+			strategy:      StrategyConstant,
+			baseDelay:     0,
+			maxDelay:      time.Minute,
+			expectedError: "base delay must be positive",
+		},
+		{
+			name:          "max delay below base delay",
+			strategy:      StrategyExponential,
+			baseDelay:     time.Minute,
+			maxDelay:      time.Second,
+			expectedError: "must not be less than base delay",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBackoff(tt.strategy, tt.baseDelay, tt.maxDelay)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, b)
+			}
+		})
+	}
+}
+
+type recordingBackoff struct {
+	prevs []time.Duration
+}
+
+func (b *recordingBackoff) Next(attempt int, prev time.Duration) time.Duration {
+	b.prevs = append(b.prevs, prev)
+	return time.Duration(attempt) * time.Millisecond
+}
+
+func TestSetBackoff(t *testing.T) {
+	restoreFn := setRetryTimings(t)
+	defer restoreFn()
+
+	b := &recordingBackoff{}
+	SetBackoff("backoff-operation", b)
+	defer SetBackoff("backoff-operation", nil)
+
+	_, err := LimitedRetryWithContext(context.Background(), "backoff-operation", func() (string, error) {
+		return "", errors.New("persistent error")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, []time.Duration{0, time.Millisecond, 2 * time.Millisecond}, b.prevs, "unexpected previous delays passed to backoff")
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"fmt"
 	"task-runner-launcher/internal/logs"
 	"time"
@@ -22,16 +23,25 @@ type retryConfig struct {
 	MaxAttempts int
 
 	// WaitTimeBetweenRetries is the time (in seconds) to wait between retries.
+	// Disregarded if Backoff is set.
 	WaitTimeBetweenRetries time.Duration
+
+	// Backoff computes the time to wait between retries, if set.
+	Backoff Backoff
 }
 
-func retry[T any](operationName string, operationFn func() (T, error), cfg retryConfig) (T, error) {
+func retry[T any](ctx context.Context, operationName string, operationFn func() (T, error), cfg retryConfig) (T, error) {
 	var lastErr error
 	var zero T
 	startTime := time.Now()
 	attempt := 1
+	var wait time.Duration
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+
 		if cfg.MaxRetryTime > 0 && time.Since(startTime) > cfg.MaxRetryTime {
 			return zero, fmt.Errorf(
 				"gave up retrying operation `%s` on reaching max retry time %v, last error: %w",
@@ -57,26 +67,55 @@ func retry[T any](operationName string, operationFn func() (T, error), cfg retry
 
 		lastErr = err
 		logs.Debugf("Attempt %d for operation `%s` failed, error: %v", attempt, operationName, err)
+
+		if cfg.Backoff != nil {
+			wait = cfg.Backoff.Next(attempt, wait)
+		} else {
+			wait = cfg.WaitTimeBetweenRetries
+		}
 		attempt++
 
-		time.Sleep(cfg.WaitTimeBetweenRetries)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, ctx.Err()
+		case <-timer.C:
+		}
 	}
 }
 
 // UnlimitedRetry retries an operation forever.
 func UnlimitedRetry[T any](operationName string, operationFn func() (T, error)) (T, error) {
-	return retry(operationName, operationFn, retryConfig{
+	return UnlimitedRetryWithContext(context.Background(), operationName, operationFn)
+}
+
+// UnlimitedRetryWithContext retries an operation until it succeeds or until
+// the context is cancelled, in which case it returns the context's error. The
+// wait between retries follows the backoff set for the operation, if any.
+func UnlimitedRetryWithContext[T any](ctx context.Context, operationName string, operationFn func() (T, error)) (T, error) {
+	return retry(ctx, operationName, operationFn, retryConfig{
 		MaxRetryTime:           0,
 		MaxAttempts:            0,
 		WaitTimeBetweenRetries: DefaultWaitTimeBetweenRetries,
+		Backoff:                backoffFor(operationName),
 	})
 }
 
 // LimitedRetry retries an operation until max retry time or until max attempts.
 func LimitedRetry[T any](operationName string, operationFn func() (T, error)) (T, error) {
-	return retry(operationName, operationFn, retryConfig{
+	return LimitedRetryWithContext(context.Background(), operationName, operationFn)
+}
+
+// LimitedRetryWithContext retries an operation until max retry time, until max
+// attempts, or until the context is cancelled, in which case it returns the
+// context's error. The wait between retries follows the backoff set for the
+// operation, if any.
+func LimitedRetryWithContext[T any](ctx context.Context, operationName string, operationFn func() (T, error)) (T, error) {
+	return retry(ctx, operationName, operationFn, retryConfig{
 		MaxRetryTime:           DefaultMaxRetryTime,
 		MaxAttempts:            DefaultMaxRetries,
 		WaitTimeBetweenRetries: DefaultWaitTimeBetweenRetries,
+		Backoff:                backoffFor(operationName),
 	})
 }
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -199,7 +200,7 @@ func TestRetryConfiguration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := retry("test", tt.fn, tt.cfg)
+			_, err := retry(context.Background(), "test", tt.fn, tt.cfg)
 			assert.Error(t, err)
 			assert.Equal(t, tt.want.Error(), err.Error())
 		})
@@ -238,3 +239,49 @@ func TestRetryWithDifferentTypes(t *testing.T) {
 		assert.Equal(t, "test", result.value)
 	})
 }
+
+func TestRetryWithContext(t *testing.T) {
+	restoreFn := setRetryTimings(t)
+	defer restoreFn()
+
+	failingFn := func() (string, error) {
+		return "", errors.New("persistent error")
+	}
+
+	t.Run("unlimited retry returns on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := UnlimitedRetryWithContext(ctx, "test-operation", failingFn)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), DefaultMaxRetryTime, "Expected prompt return on cancellation")
+	})
+
+	t.Run("limited retry returns on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		callCount := 0
+		_, err := LimitedRetryWithContext(ctx, "test-operation", func() (string, error) {
+			callCount++
+			return failingFn()
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, callCount, "Expected no attempts with cancelled context")
+	})
+
+	t.Run("interrupts wait between retries", func(t *testing.T) {
+		cfg := retryConfig{WaitTimeBetweenRetries: time.Hour}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := retry(ctx, "test-operation", failingFn, cfg)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second, "Expected wait to be interrupted")
+	})
+}
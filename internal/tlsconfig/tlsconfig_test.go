@@ -0,0 +1,61 @@
+package tlsconfig
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes the PEM block of the given type to a file and returns its path.
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "file.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+
+	return path
+}
+
+func TestNew(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	caFile := writePEM(t, "CERTIFICATE", srv.Certificate().Raw)
+
+	t.Run("no settings", func(t *testing.T) {
+		tlsConfig, err := New("", "", "", "")
+		require.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("CA file and server name", func(t *testing.T) {
+		tlsConfig, err := New(caFile, "", "", "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", tlsConfig.ServerName)
+		assert.NotNil(t, tlsConfig.RootCAs)
+	})
+
+	t.Run("missing CA file", func(t *testing.T) {
+		_, err := New(filepath.Join(t.TempDir(), "missing.pem"), "", "", "")
+		assert.ErrorContains(t, err, "failed to read CA file")
+	})
+
+	t.Run("CA file without certs", func(t *testing.T) {
+		emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+		require.NoError(t, os.WriteFile(emptyFile, []byte("not a cert"), 0600))
+
+		_, err := New(emptyFile, "", "", "")
+		assert.ErrorContains(t, err, "contains no PEM certs")
+	})
+
+	t.Run("key without cert", func(t *testing.T) {
+		_, err := New("", "", caFile, "")
+		assert.ErrorContains(t, err, "must be set together")
+	})
+}
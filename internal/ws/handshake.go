@@ -1,12 +1,18 @@
 package ws
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"sync"
 	"task-runner-launcher/internal/errs"
+	"task-runner-launcher/internal/http"
 	"task-runner-launcher/internal/logs"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -20,6 +26,15 @@ const (
 	msgBrokerTaskOfferAccept  = "broker:taskofferaccept"
 )
 
+// closeTimeout is the max time to wait for the close frame to be written on shutdown.
+const closeTimeout = 1 * time.Second
+
+// Defaults of the keepalive of the websocket connection with the task broker.
+const (
+	DefaultPingInterval = 30 * time.Second
+	DefaultPongTimeout  = 10 * time.Second
+)
+
 type message struct {
 	Type     string   `json:"type"`
 	Types    []string `json:"types,omitempty"`    // for runner:info
@@ -34,6 +49,21 @@ type HandshakeConfig struct {
 	TaskType            string
 	TaskBrokerServerURI string
 	GrantToken          string
+	UserAgent           string // optional
+
+	// PingInterval is the interval between pings sent to the task broker while
+	// waiting for the task offer to be accepted. Default: `DefaultPingInterval`.
+	PingInterval time.Duration
+
+	// PongTimeout is how long to wait for the pong to a ping before considering
+	// the connection dropped. Default: `DefaultPongTimeout`.
+	PongTimeout time.Duration
+
+	// OfferRenewalInterval is how long each task offer is valid for. An offer
+	// is renewed only once the previous one has expired, so that the broker
+	// never holds two offers of the launcher that it could both accept. If
+	// zero, a single non-expiring offer is sent instead.
+	OfferRenewalInterval time.Duration
 }
 
 func validateConfig(cfg HandshakeConfig) error {
@@ -62,7 +92,15 @@ func buildWebsocketURL(taskBrokerServerURI, runnerID string) (*url.URL, error) {
 		return nil, fmt.Errorf("task broker URI must have no query params")
 	}
 
-	u.Scheme = "ws"
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "unix":
+		u.Scheme = "ws"
+		u.Host = "localhost" // disregarded, as the socket is dialed instead
+	default:
+		u.Scheme = "ws"
+	}
 	u.Path = "/runners/_ws"
 
 	q := u.Query()
@@ -72,17 +110,29 @@ func buildWebsocketURL(taskBrokerServerURI, runnerID string) (*url.URL, error) {
 	return u, nil
 }
 
-func connectToWebsocket(wsURL *url.URL, grantToken string, logger *logs.Logger) (*websocket.Conn, error) {
+// connectToWebsocket connects to the task broker's websocket at the URL, over
+// the unix domain socket at `socketPath` if not empty.
+func connectToWebsocket(ctx context.Context, wsURL *url.URL, socketPath, grantToken, userAgent string, logger *logs.Logger) (*websocket.Conn, error) {
 	reqHeader := map[string][]string{
 		"Authorization": {fmt.Sprintf("Bearer %s", grantToken)},
 	}
+	if userAgent != "" {
+		reqHeader["User-Agent"] = []string{userAgent}
+	}
 
 	dialer := websocket.Dialer{
 		ReadBufferSize:  512,
 		WriteBufferSize: 512,
+		TLSClientConfig: http.BrokerTLSConfig(),
+		Proxy:           http.BrokerProxy,
+	}
+
+	if socketPath != "" {
+		dialer.NetDialContext = http.DialUnixSocket(socketPath)
+		dialer.Proxy = nil
 	}
 
-	wsConn, _, err := dialer.Dial(wsURL.String(), reqHeader)
+	wsConn, _, err := dialer.DialContext(ctx, wsURL.String(), reqHeader)
 	if err != nil {
 		return nil, fmt.Errorf("websocket connection failed: %w", err)
 	}
@@ -92,6 +142,54 @@ func connectToWebsocket(wsURL *url.URL, grantToken string, logger *logs.Logger)
 	return wsConn, nil
 }
 
+// keepAlive pings the task broker every `pingInterval` until `done` is closed.
+// Pings are written with `WriteControl`, which is safe to call concurrently
+// with the handshake's writes. A failed ping is disregarded, as the read
+// deadline then detects the dropped connection.
+func keepAlive(wsConn *websocket.Conn, pingInterval, pongTimeout time.Duration, done <-chan struct{}, logger *logs.Logger) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := wsConn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pongTimeout)); err != nil {
+				logger.Debugf("Failed to send ping: %v", err)
+			}
+		}
+	}
+}
+
+// offerExpiryMargin is the wait after an offer expires before renewing it, so
+// that the previous offer has also expired on the broker, despite latency.
+const offerExpiryMargin = 100 * time.Millisecond
+
+// renewOffers sends a task offer every `interval` until `done` is closed or
+// sending an offer fails.
+func renewOffers(interval time.Duration, sendOffer func() error, done <-chan struct{}) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+			if err := sendOffer(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// isTimeoutError returns whether the error is a read deadline being exceeded.
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 func randomID() string {
 	b := make([]byte, 8)
 	_, _ = rand.Read(b)
@@ -103,16 +201,39 @@ func isWsCloseError(err error) bool {
 	return ok
 }
 
+// closeGracefully sends a close frame to the task broker before closing the
+// connection, so that the broker can deregister the launcher right away.
+func closeGracefully(wsConn *websocket.Conn) {
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "launcher shutting down")
+	_ = wsConn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(closeTimeout))
+	wsConn.Close()
+}
+
 // Handshake is the flow where the launcher connects via websocket with task broker,
 // registers, sends a non-expiring task offer, and receives the accept for that
-// offer. Note that the handshake completes only once this task offer is accepted,
-// which may take time.
-func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
+// offer. With an offer renewal interval, the launcher instead sends expiring
+// offers, one at a time, until one is accepted. Note that the handshake
+// completes only once a task offer is accepted, which may take time.
+// Meanwhile, the launcher pings the task broker, and returns
+// `errs.ErrServerDown` if neither a pong nor any message arrives in time, e.g.
+// after the connection was silently dropped by a NAT or load balancer. If the
+// context is cancelled before the offer is accepted, the connection is closed
+// cleanly and the context's error is returned.
+func Handshake(ctx context.Context, cfg HandshakeConfig, logger *logs.Logger) error {
 	if err := validateConfig(cfg); err != nil {
 		return fmt.Errorf("received invalid handshake config: %w", err)
 	}
 
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = DefaultPingInterval
+	}
+
+	if cfg.PongTimeout <= 0 {
+		cfg.PongTimeout = DefaultPongTimeout
+	}
+
 	runnerID := randomID()
+	logger.SetLauncherID(runnerID)
 	logger.Debugf("Launcher ID: %s", runnerID)
 
 	wsURL, err := buildWebsocketURL(cfg.TaskBrokerServerURI, runnerID)
@@ -120,13 +241,70 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 		return fmt.Errorf("failed to build websocket URL: %w", err)
 	}
 
-	wsConn, err := connectToWebsocket(wsURL, cfg.GrantToken, logger)
+	socketPath, _ := http.UnixSocketPath(cfg.TaskBrokerServerURI)
+	wsConn, err := connectToWebsocket(ctx, wsURL, socketPath, cfg.GrantToken, cfg.UserAgent, logger)
 	if err != nil {
 		return err
 	}
 
-	errReceived := make(chan error)
+	// any message or pong within the read deadline shows the connection is alive
+	readDeadline := cfg.PingInterval + cfg.PongTimeout
+	extendReadDeadline := func() {
+		_ = wsConn.SetReadDeadline(time.Now().Add(readDeadline))
+	}
+	extendReadDeadline()
+	wsConn.SetPongHandler(func(string) error {
+		extendReadDeadline()
+		return nil
+	})
+
+	// writes are serialized, as offers may be renewed while handling messages
+	var writeMu sync.Mutex
+	writeJSON := func(msg message) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		return wsConn.WriteJSON(msg)
+	}
+
+	errReceived := make(chan error, 1)
+	offerFailed := make(chan error, 1)
 	handshakeComplete := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go keepAlive(wsConn, cfg.PingInterval, cfg.PongTimeout, stop, logger)
+
+	validFor := -1 // non-expiring offer
+	if cfg.OfferRenewalInterval > 0 {
+		validFor = int(cfg.OfferRenewalInterval / time.Millisecond)
+	}
+
+	sendOffer := func() error {
+		msg := message{
+			Type:     msgRunnerTaskOffer,
+			TaskType: cfg.TaskType,
+			OfferID:  randomID(),
+			ValidFor: validFor,
+		}
+
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		select {
+		case <-handshakeComplete:
+			return nil // offer already accepted, so no need to renew
+		default:
+		}
+
+		if err := wsConn.WriteJSON(msg); err != nil {
+			return fmt.Errorf("failed to send task offer: %w", err)
+		}
+
+		logger.Debugf("-> Sent message `%s` for offer ID `%s`", msg.Type, msg.OfferID)
+
+		return nil
+	}
 
 	go func() {
 		defer close(errReceived)
@@ -138,6 +316,9 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 				switch {
 				case isWsCloseError(err):
 					errReceived <- errs.ErrServerDown
+				case isTimeoutError(err):
+					logger.Warnf("No message or pong received from task broker within %s", readDeadline)
+					errReceived <- errs.ErrServerDown
 				case err == websocket.ErrReadLimit:
 					errReceived <- errs.ErrWsMsgTooLarge
 				default:
@@ -146,6 +327,8 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 				return
 			}
 
+			extendReadDeadline()
+
 			logger.Debugf("<- Received message `%s`", msg.Type)
 
 			switch msg.Type {
@@ -155,7 +338,7 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 					Types: []string{cfg.TaskType},
 					Name:  fmt.Sprintf("launcher-%s", cfg.TaskType),
 				}
-				if err := wsConn.WriteJSON(msg); err != nil {
+				if err := writeJSON(msg); err != nil {
 					errReceived <- fmt.Errorf("failed to send runner info: %w", err)
 					return
 				}
@@ -163,31 +346,35 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 				logger.Debugf("-> Sent message `%s`", msg.Type)
 
 			case msgBrokerRunnerRegistered:
-				msg := message{
-					Type:     msgRunnerTaskOffer,
-					TaskType: cfg.TaskType,
-					OfferID:  randomID(),
-					ValidFor: -1, // non-expiring offer
-				}
-
-				if err := wsConn.WriteJSON(msg); err != nil {
-					errReceived <- fmt.Errorf("failed to send task offer: %w", err)
+				if err := sendOffer(); err != nil {
+					errReceived <- err
 					return
 				}
 
-				logger.Debugf("-> Sent message `%s` for offer ID `%s`", msg.Type, msg.OfferID)
 				logger.Info("Waiting for launcher's task offer to be accepted...")
 
+				if cfg.OfferRenewalInterval > 0 {
+					go func() {
+						if err := renewOffers(cfg.OfferRenewalInterval+offerExpiryMargin, sendOffer, stop); err != nil {
+							offerFailed <- err
+						}
+					}()
+				}
+
 			case msgBrokerTaskOfferAccept:
 				msg := message{
 					Type:   msgRunnerTaskDeferred,
 					TaskID: msg.TaskID,
 				}
 
+				writeMu.Lock()
 				if err := wsConn.WriteJSON(msg); err != nil {
+					writeMu.Unlock()
 					errReceived <- fmt.Errorf("failed to defer task: %w", err)
 					return
 				}
+				close(handshakeComplete) // under lock, so that no offer is renewed after the accept
+				writeMu.Unlock()
 
 				logger.Debugf("-> Sent message `%s` for task ID `%s`", msg.Type, msg.TaskID)
 
@@ -195,8 +382,6 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 
 				logger.Debugf("Disconnected: %s", wsURL.String())
 
-				close(handshakeComplete)
-
 				return
 			}
 		}
@@ -206,8 +391,15 @@ func Handshake(cfg HandshakeConfig, logger *logs.Logger) error {
 	case err := <-errReceived:
 		wsConn.Close()
 		return err
+	case err := <-offerFailed:
+		wsConn.Close()
+		return err
 	case <-handshakeComplete:
 		logger.Debug("Runner's task offer was accepted")
 		return nil
+	case <-ctx.Done():
+		closeGracefully(wsConn)
+		logger.Debugf("Disconnected: %s", wsURL.String())
+		return ctx.Err()
 	}
 }
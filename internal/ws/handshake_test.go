@@ -1,9 +1,13 @@
 package ws
 
 import (
+	"context"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"task-runner-launcher/internal/errs"
 	"task-runner-launcher/internal/logs"
@@ -145,7 +149,7 @@ func TestHandshake(t *testing.T) {
 			}
 
 			logger := logs.NewLogger(logs.InfoLevel, "")
-			err := Handshake(tt.config, logger)
+			err := Handshake(context.Background(), tt.config, logger)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -157,6 +161,42 @@ func TestHandshake(t *testing.T) {
 	}
 }
 
+func TestHandshakeOverUnixSocket(t *testing.T) {
+	socketDir, err := os.MkdirTemp("", "ws") // short path, as socket paths are limited in length
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(socketDir) })
+
+	socketPath := filepath.Join(socketDir, "broker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-agent", r.UserAgent())
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err, "Failed to upgrade connection")
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(message{Type: msgBrokerTaskOfferAccept, TaskID: "test-task-id"}))
+
+		var msg message
+		require.NoError(t, conn.ReadJSON(&msg), "Failed to read `runner:taskdeferred`")
+		assert.Equal(t, msgRunnerTaskDeferred, msg.Type, "Unexpected message type")
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	cfg := HandshakeConfig{
+		TaskType:            "javascript",
+		TaskBrokerServerURI: "unix://" + socketPath,
+		GrantToken:          "test-token",
+		UserAgent:           "test-agent",
+	}
+
+	assert.NoError(t, Handshake(context.Background(), cfg, logs.NewLogger(logs.InfoLevel, "")))
+}
+
 func TestRandomID(t *testing.T) {
 	seen := make(map[string]bool)
 	iterations := 1000
@@ -169,6 +209,48 @@ func TestRandomID(t *testing.T) {
 	}
 }
 
+func TestBuildWebsocketURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		brokerURI     string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:      "http maps to ws",
+			brokerURI: "http://127.0.0.1:5679",
+			expected:  "ws://127.0.0.1:5679/runners/_ws?id=abc",
+		},
+		{
+			name:      "https maps to wss",
+			brokerURI: "https://n8n.example.com",
+			expected:  "wss://n8n.example.com/runners/_ws?id=abc",
+		},
+		{
+			name:      "unix socket maps to ws",
+			brokerURI: "unix:///run/n8n/broker.sock",
+			expected:  "ws://localhost/runners/_ws?id=abc",
+		},
+		{
+			name:          "query params",
+			brokerURI:     "http://127.0.0.1:5679?foo=bar",
+			expectedError: "must have no query params",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := buildWebsocketURL(tt.brokerURI, "abc")
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, u.String())
+		})
+	}
+}
+
 func TestIsWsCloseError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -222,7 +304,7 @@ func TestHandshakeTimeout(t *testing.T) {
 	done := make(chan error)
 	go func() {
 		logger := logs.NewLogger(logs.InfoLevel, "")
-		done <- Handshake(HandshakeConfig{
+		done <- Handshake(context.Background(), HandshakeConfig{
 			TaskType:            "javascript",
 			TaskBrokerServerURI: "http://" + srv.Listener.Addr().String(),
 			GrantToken:          "test-token",
@@ -236,3 +318,163 @@ func TestHandshakeTimeout(t *testing.T) {
 		t.Error("Test timed out")
 	}
 }
+
+func TestHandshakeContextCancellation(t *testing.T) {
+	closeReceived := make(chan int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err, "Failed to upgrade connection")
+		defer conn.Close()
+
+		err = conn.WriteJSON(message{Type: msgBrokerInfoRequest})
+		require.NoError(t, err, "Failed to write `broker:inforequest`")
+
+		var msg message
+		require.NoError(t, conn.ReadJSON(&msg), "Failed to read `runner:info`")
+
+		err = conn.WriteJSON(message{Type: msgBrokerRunnerRegistered})
+		require.NoError(t, err, "Failed to write `broker:runnerregistered`")
+
+		require.NoError(t, conn.ReadJSON(&msg), "Failed to read `runner:taskoffer`")
+
+		// never accept the offer, wait for the launcher to close the connection
+		err = conn.ReadJSON(&msg)
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			closeReceived <- closeErr.Code
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		logger := logs.NewLogger(logs.InfoLevel, "")
+		done <- Handshake(ctx, HandshakeConfig{
+			TaskType:            "javascript",
+			TaskBrokerServerURI: "http://" + srv.Listener.Addr().String(),
+			GrantToken:          "test-token",
+		}, logger)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled, "Expected context cancellation error")
+	case <-time.After(time.Second):
+		t.Fatal("Handshake did not return after context cancellation")
+	}
+
+	select {
+	case code := <-closeReceived:
+		assert.Equal(t, websocket.CloseNormalClosure, code, "Unexpected close code")
+	case <-time.After(time.Second):
+		t.Error("Server did not receive close frame")
+	}
+}
+
+func TestHandshakeKeepAlive(t *testing.T) {
+	t.Run("missed pong means server down", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err, "Failed to upgrade connection")
+			defer conn.Close()
+
+			<-release // never read, so never answer pings, as on a dropped connection
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		err := Handshake(context.Background(), HandshakeConfig{
+			TaskType:            "javascript",
+			TaskBrokerServerURI: "http://" + srv.Listener.Addr().String(),
+			GrantToken:          "test-token",
+			PingInterval:        20 * time.Millisecond,
+			PongTimeout:         20 * time.Millisecond,
+		}, logs.NewLogger(logs.InfoLevel, ""))
+
+		assert.ErrorIs(t, err, errs.ErrServerDown)
+	})
+
+	t.Run("pongs keep connection alive", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			require.NoError(t, err, "Failed to upgrade connection")
+			defer conn.Close()
+
+			received := make(chan message)
+			go func() {
+				defer close(received)
+				for { // reading answers pings with pongs
+					var msg message
+					if err := conn.ReadJSON(&msg); err != nil {
+						return
+					}
+					received <- msg
+				}
+			}()
+
+			time.Sleep(200 * time.Millisecond) // well beyond ping interval plus pong timeout
+
+			require.NoError(t, conn.WriteJSON(message{Type: msgBrokerTaskOfferAccept, TaskID: "test-task-id"}))
+			assert.Equal(t, msgRunnerTaskDeferred, (<-received).Type, "Unexpected message type")
+		}))
+		defer srv.Close()
+
+		err := Handshake(context.Background(), HandshakeConfig{
+			TaskType:            "javascript",
+			TaskBrokerServerURI: "http://" + srv.Listener.Addr().String(),
+			GrantToken:          "test-token",
+			PingInterval:        20 * time.Millisecond,
+			PongTimeout:         20 * time.Millisecond,
+		}, logs.NewLogger(logs.InfoLevel, ""))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestHandshakeOfferRenewal(t *testing.T) {
+	offers := make(chan message, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err, "Failed to upgrade connection")
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(message{Type: msgBrokerRunnerRegistered}))
+
+		var msg message
+		for range 3 {
+			require.NoError(t, conn.ReadJSON(&msg), "Failed to read `runner:taskoffer`")
+			offers <- msg
+		}
+
+		require.NoError(t, conn.WriteJSON(message{Type: msgBrokerTaskOfferAccept, TaskID: "test-task-id"}))
+
+		for msg.Type == msgRunnerTaskOffer { // skip any offer renewed before the accept
+			require.NoError(t, conn.ReadJSON(&msg), "Failed to read `runner:taskdeferred`")
+		}
+		assert.Equal(t, msgRunnerTaskDeferred, msg.Type, "Unexpected message type")
+	}))
+	defer srv.Close()
+
+	err := Handshake(context.Background(), HandshakeConfig{
+		TaskType:             "javascript",
+		TaskBrokerServerURI:  "http://" + srv.Listener.Addr().String(),
+		GrantToken:           "test-token",
+		OfferRenewalInterval: 20 * time.Millisecond,
+	}, logs.NewLogger(logs.InfoLevel, ""))
+	require.NoError(t, err)
+
+	close(offers)
+	offerIDs := make(map[string]bool)
+	for offer := range offers {
+		assert.Equal(t, msgRunnerTaskOffer, offer.Type, "Unexpected message type")
+		assert.Equal(t, 20, offer.ValidFor, "Unexpected ValidFor value")
+		offerIDs[offer.OfferID] = true
+	}
+	assert.Len(t, offerIDs, 3, "Expected a new offer ID per renewal")
+}